@@ -0,0 +1,415 @@
+package asm
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// Error is an error in the source, at the line it happened.
+type Error struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// maxErrors is the number of errors after which Assemble gives up.
+const maxErrors = 10
+
+// statement is an instruction or data directive in the source.
+type statement struct {
+	file string
+	line int
+	addr int
+
+	// mnemonic is upper case, or "db" or "dw" for data
+	mnemonic string
+	operands []string
+	form     *form
+}
+
+func (s *statement) size() int {
+	switch s.mnemonic {
+	case "db":
+		return len(s.operands)
+	case "dw":
+		return 2 * len(s.operands)
+	}
+	return s.form.size()
+}
+
+// constant is a name defined with NAME = expr.
+type constant struct {
+	file string
+	line int
+	expr string
+
+	value     int
+	evaluated bool
+	resolving bool
+}
+
+// assembler holds the source being assembled.
+type assembler struct {
+	readFile func(name string) ([]byte, error)
+
+	statements []*statement
+	labels     map[string]int
+	constants  map[string]*constant
+
+	// files being read, to catch include cycles
+	including []string
+
+	addr int
+	errs []error
+}
+
+// Assemble assembles the file called name into a ROM to be loaded at
+// chip8.ProgramStart. Files are read with readFile, usually os.ReadFile,
+// and included files are found relative to the file including them.
+//
+// The source has a statement per line: an instruction in the syntax of
+// chip8.Instruction.String, like "LD V1, 0A" or "DRW V0, V1, 5", or one of
+// the directives
+//
+//	db byte, ...       bytes
+//	dw word, ...       16 bit words, most significant byte first
+//	include "file"     the statements of another file
+//	NAME = expr        a constant
+//
+// A statement can be preceded by a label, like "loop: JP loop" or "loop:"
+// alone, naming the address of the statement. Comments start with ; and go
+// until the end of the line.
+//
+// Numbers are in hex, like "0A", "0x0A" or "A", or in binary when prefixed
+// by %, like "%00111100". Names that are valid hex numbers are read as
+// numbers. Operands taking a number also take labels, constants and sums
+// and subtractions of them, like "sprites + 5".
+//
+// All the errors found, up to a limit, are returned together as *Error.
+func Assemble(name string, readFile func(name string) ([]byte, error)) ([]byte, error) {
+	a := &assembler{
+		readFile:  readFile,
+		labels:    map[string]int{},
+		constants: map[string]*constant{},
+		addr:      chip8.ProgramStart,
+	}
+	if err := a.parseFile(name, name, 0); err != nil {
+		return nil, err
+	}
+
+	rom := make([]byte, 0, a.addr-chip8.ProgramStart)
+	for _, s := range a.statements {
+		b, err := a.encode(s)
+		if err != nil {
+			if err := a.errorf(s.file, s.line, "%w", err); err != nil {
+				return nil, err
+			}
+			b = make([]byte, s.size())
+		}
+		rom = append(rom, b...)
+	}
+	if len(a.errs) > 0 {
+		return nil, errors.Join(a.errs...)
+	}
+	if len(rom) > 0x10000-chip8.ProgramStart {
+		return nil, fmt.Errorf("program too large: %d bytes", len(rom))
+	}
+	return rom, nil
+}
+
+// errorf records an error, returning a non nil error once there are too
+// many to go on.
+func (a *assembler) errorf(file string, line int, format string, args ...any) error {
+	a.errs = append(a.errs, &Error{File: file, Line: line, Err: fmt.Errorf(format, args...)})
+	if len(a.errs) >= maxErrors {
+		return errors.Join(append(a.errs, errors.New("too many errors"))...)
+	}
+	return nil
+}
+
+// parseFile reads the statements of a file, found at path and included as
+// name from the line of the file that includes it.
+func (a *assembler) parseFile(path, name string, line int) error {
+	for _, f := range a.including {
+		if f == path {
+			return a.errorf(a.including[len(a.including)-1], line, "include cycle with %q", name)
+		}
+	}
+
+	src, err := a.readFile(path)
+	if err != nil {
+		if len(a.including) == 0 {
+			return err
+		}
+		return a.errorf(a.including[len(a.including)-1], line, "%w", err)
+	}
+
+	a.including = append(a.including, path)
+	defer func() { a.including = a.including[:len(a.including)-1] }()
+
+	for i, text := range strings.Split(string(src), "\n") {
+		if err := a.parseLine(path, i+1, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseLine reads the statement in a line.
+func (a *assembler) parseLine(file string, line int, text string) error {
+	if i := strings.IndexByte(text, ';'); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+
+	// labels
+	for {
+		i := strings.IndexByte(text, ':')
+		if i < 0 || !isName(strings.TrimSpace(text[:i])) {
+			break
+		}
+		name := strings.TrimSpace(text[:i])
+		text = strings.TrimSpace(text[i+1:])
+		if err := a.define(name); err != nil {
+			if err := a.errorf(file, line, "%w", err); err != nil {
+				return err
+			}
+			continue
+		}
+		a.labels[name] = a.addr
+	}
+	if text == "" {
+		return nil
+	}
+
+	// constants
+	if name, expr, ok := strings.Cut(text, "="); ok && isName(strings.TrimSpace(name)) {
+		name = strings.TrimSpace(name)
+		if err := a.define(name); err != nil {
+			return a.errorf(file, line, "%w", err)
+		}
+		a.constants[name] = &constant{file: file, line: line, expr: strings.TrimSpace(expr)}
+		return nil
+	}
+
+	mnemonic, rest := text, ""
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		mnemonic, rest = text[:i], text[i:]
+	}
+	var operands []string
+	if rest = strings.TrimSpace(rest); rest != "" {
+		for _, op := range strings.Split(rest, ",") {
+			operands = append(operands, strings.TrimSpace(op))
+		}
+	}
+
+	s := &statement{file: file, line: line, addr: a.addr, mnemonic: strings.ToUpper(mnemonic), operands: operands}
+	switch strings.ToLower(mnemonic) {
+	case "include":
+		inc, err := strconv.Unquote(rest)
+		if err != nil {
+			return a.errorf(file, line, "include wants a quoted file name, got %s", rest)
+		}
+		return a.parseFile(filepath.Join(filepath.Dir(file), inc), inc, line)
+	case "db", "dw":
+		s.mnemonic = strings.ToLower(mnemonic)
+		if len(operands) == 0 {
+			return a.errorf(file, line, "%s without values", s.mnemonic)
+		}
+	default:
+		f, err := match(s.mnemonic, operands)
+		if err != nil {
+			return a.errorf(file, line, "%w", err)
+		}
+		s.form = f
+	}
+
+	a.statements = append(a.statements, s)
+	a.addr += s.size()
+	return nil
+}
+
+// define checks that a label or constant can be called name.
+func (a *assembler) define(name string) error {
+	if _, ok := a.labels[name]; ok {
+		return fmt.Errorf("%s redefined", name)
+	}
+	if _, ok := a.constants[name]; ok {
+		return fmt.Errorf("%s redefined", name)
+	}
+	if _, err := parseNumber(name); err == nil {
+		return fmt.Errorf("name %s is a hex number", name)
+	}
+	if isRegister(name) || reserved[strings.ToUpper(name)] {
+		return fmt.Errorf("name %s is reserved", name)
+	}
+	return nil
+}
+
+// reserved are the names with a meaning in operands.
+var reserved = map[string]bool{
+	"I": true, "DT": true, "ST": true, "K": true, "F": true, "HF": true, "R": true, "LONG": true,
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		letter := r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isRegister(s string) bool {
+	_, ok := register(s)
+	return ok
+}
+
+// register parses a register name, V0 to VF.
+func register(s string) (uint16, bool) {
+	if len(s) != 2 || s[0] != 'V' && s[0] != 'v' {
+		return 0, false
+	}
+	x, err := strconv.ParseUint(s[1:], 16, 4)
+	return uint16(x), err == nil
+}
+
+// parseNumber parses a number in hex, with an optional 0x, or in binary
+// prefixed by %.
+func parseNumber(s string) (int, error) {
+	base, digits := 16, s
+	if rest, ok := strings.CutPrefix(s, "%"); ok {
+		base, digits = 2, rest
+	} else if rest, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		digits = rest
+	}
+	n, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return int(n), nil
+}
+
+// eval evaluates an expression: numbers, labels and constants added and
+// subtracted.
+func (a *assembler) eval(expr string) (int, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, errors.New("missing value")
+	}
+
+	total, sign := 0, 1
+	for {
+		i := strings.IndexAny(expr, "+-")
+		term := strings.TrimSpace(expr)
+		if i >= 0 {
+			term = strings.TrimSpace(expr[:i])
+		}
+		v, err := a.term(term)
+		if err != nil {
+			return 0, err
+		}
+		total += sign * v
+		if i < 0 {
+			return total, nil
+		}
+		sign = 1
+		if expr[i] == '-' {
+			sign = -1
+		}
+		expr = expr[i+1:]
+	}
+}
+
+func (a *assembler) term(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("missing value")
+	}
+	if n, err := parseNumber(s); err == nil {
+		return n, nil
+	}
+	if addr, ok := a.labels[s]; ok {
+		return addr, nil
+	}
+	c, ok := a.constants[s]
+	if !ok {
+		if isName(s) {
+			return 0, fmt.Errorf("undefined: %s", s)
+		}
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	if !c.evaluated {
+		if c.resolving {
+			return 0, fmt.Errorf("constant %s defined in terms of itself", s)
+		}
+		c.resolving = true
+		v, err := a.eval(c.expr)
+		c.resolving = false
+		if err != nil {
+			return 0, fmt.Errorf("%s (at %s:%d): %w", s, c.file, c.line, err)
+		}
+		c.value, c.evaluated = v, true
+	}
+	return c.value, nil
+}
+
+// value evaluates an operand that must fit in max.
+func (a *assembler) value(expr string, max int) (int, error) {
+	v, err := a.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || v > max {
+		if _, err := parseNumber(expr); err == nil {
+			return 0, fmt.Errorf("%s out of range, max is %X", expr, max)
+		}
+		return 0, fmt.Errorf("%s = %X out of range, max is %X", expr, v, max)
+	}
+	return v, nil
+}
+
+// encode returns the bytes of a statement.
+func (a *assembler) encode(s *statement) ([]byte, error) {
+	switch s.mnemonic {
+	case "db":
+		b := make([]byte, len(s.operands))
+		for i, op := range s.operands {
+			v, err := a.value(op, 0xFF)
+			if err != nil {
+				return nil, err
+			}
+			b[i] = byte(v)
+		}
+		return b, nil
+	case "dw":
+		b := make([]byte, 0, 2*len(s.operands))
+		for _, op := range s.operands {
+			v, err := a.value(op, 0xFFFF)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, byte(v>>8), byte(v))
+		}
+		return b, nil
+	}
+	return s.form.encode(a, s.operands)
+}
@@ -0,0 +1,168 @@
+package asm
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// files returns a readFile serving the sources in srcs.
+func files(srcs map[string]string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		src, ok := srcs[name]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	readFile := files(map[string]string{
+		"main.8s": `
+; draws a box and waits for a key
+SPEED = 0A
+X = SPEED + 2
+
+start:	CLS
+	LD V0, X          ; comments go anywhere
+	ld v1, 0x1
+	LD I, box
+	DRW V0, V1, B
+loop:
+	LD V2, K
+	SE V2, SPEED - 1
+	JP loop
+	LD I, LONG words + 2
+	EXIT
+include "data/box.8s"
+`,
+		"data/box.8s": `
+box:	db %11111111, %10000001, 81, 81, 81
+	db 81, 81, 81, 81, 81, FF
+words:	dw 1234, box
+`,
+	})
+
+	got, err := Assemble("main.8s", readFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x00, 0xE0,
+		0x60, 0x0C,
+		0x61, 0x01,
+		0xA2, 0x16,
+		0xD0, 0x1B,
+		0xF2, 0x0A,
+		0x32, 0x09,
+		0x12, 0x0A,
+		0xF0, 0x00, 0x02, 0x23,
+		0x00, 0xFD,
+		0xFF, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0xFF,
+		0x12, 0x34, 0x02, 0x16,
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("want:\n% X\ngot:\n% X", want, got)
+	}
+}
+
+func TestAssemble_errors(t *testing.T) {
+	readFile := files(map[string]string{
+		"main.8s": `
+	FOO V1
+	LD V1, 100
+	JP nowhere
+	DRW V0, V1
+ADD = 5
+loop:
+loop:
+	include "loop.8s"
+`,
+		"loop.8s": `include "loop.8s"`,
+	})
+
+	_, err := Assemble("main.8s", readFile)
+	if err == nil {
+		t.Fatal("want: errors, got: nil")
+	}
+	for _, want := range []string{
+		"main.8s:2: unknown instruction FOO",
+		"main.8s:3: 100 out of range",
+		"main.8s:4: undefined: nowhere",
+		"main.8s:5: invalid operands for DRW",
+		"main.8s:6: name ADD is a hex number",
+		"main.8s:8: loop redefined",
+		`loop.8s:1: include cycle with "loop.8s"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("want: %q in the errors, got:\n%v", want, err)
+		}
+	}
+
+	var e *Error
+	if !errors.As(err, &e) || e.File != "main.8s" {
+		t.Fatalf("want: *Error in main.8s, got: %#v", err)
+	}
+
+	if _, err := Assemble("missing.8s", readFile); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("want: %v, got: %v", fs.ErrNotExist, err)
+	}
+}
+
+func TestDisassemble_roundTrip(t *testing.T) {
+	// every instruction, then random bytes which are part code and part
+	// data
+	var opcodes, ends []int
+	for _, fs := range forms {
+		for _, f := range fs {
+			switch f.opcode {
+			case 0x00EE, 0x00FD, 0x1000, 0xB000:
+				// each of the instructions that end the code goes after a
+				// skip, so the disassembler reaches what follows them
+				ends = append(ends, 0x9000, int(f.opcode))
+			default:
+				opcodes = append(opcodes, int(f.opcode))
+			}
+		}
+	}
+	sort.Ints(opcodes)
+	opcodes = append(opcodes, ends...)
+	var rom []byte
+	for _, op := range opcodes {
+		rom = append(rom, byte(op>>8), byte(op))
+		if op == 0xF000 {
+			rom = append(rom, 0x12, 0x34)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 4096; i++ {
+		rom = append(rom, byte(r.Intn(256)))
+	}
+
+	src := Disassemble(rom)
+	got, err := Assemble("rom.8s", files(map[string]string{"rom.8s": src}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, rom) {
+		t.Fatalf("want: the same %d bytes, got: %d different ones", len(rom), len(got))
+	}
+
+	code := 0
+	for _, line := range strings.Split(src, "\n") {
+		if strings.HasPrefix(line, "\tdb") {
+			break
+		}
+		if strings.HasPrefix(line, "\t") {
+			code++
+		}
+	}
+	if code < len(opcodes) {
+		t.Fatalf("want: %d instructions before the data, got: %d in\n%s", len(opcodes), code, src)
+	}
+}
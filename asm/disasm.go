@@ -0,0 +1,175 @@
+// Package asm converts between CHIP-8 programs and their assembly source,
+// written in the syntax of chip8.Instruction.String.
+package asm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// dbPerLine is the maximum number of bytes in a db directive.
+const dbPerLine = 8
+
+// disassembly is the result of tracing the code of a ROM.
+type disassembly struct {
+	rom []byte
+
+	// start tells which offsets of rom start an instruction, and code which
+	// ones are part of one
+	start []bool
+	code  []bool
+
+	// labels are the addresses referred to by the code
+	labels map[uint16]bool
+}
+
+// Disassemble returns the source of rom, loaded at chip8.ProgramStart. The
+// code is found by following the execution from the start of the program
+// through jumps, calls and skips, and everything else is emitted as db
+// directives. The addresses referred to by the code are labeled. Assembling
+// the source gives back rom.
+func Disassemble(rom []byte) string {
+	d := &disassembly{
+		rom:    rom,
+		start:  make([]bool, len(rom)),
+		code:   make([]bool, len(rom)),
+		labels: map[uint16]bool{},
+	}
+	d.trace(chip8.ProgramStart)
+	return d.source()
+}
+
+// instructionAt decodes the instruction at addr, reporting whether there is
+// a valid one entirely inside the ROM.
+func (d *disassembly) instructionAt(addr int) (chip8.Instruction, bool) {
+	off := addr - chip8.ProgramStart
+	if off < 0 || off >= len(d.rom) {
+		return chip8.Instruction{}, false
+	}
+	in := chip8.Decode(d.rom[off:])
+	return in, in.Op != chip8.OpInvalid
+}
+
+// trace marks the code reachable from entry.
+func (d *disassembly) trace(entry int) {
+	work := []int{entry}
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		in, ok := d.instructionAt(addr)
+		if !ok {
+			continue
+		}
+		off, size := addr-chip8.ProgramStart, int(in.Size())
+		if d.start[off] {
+			continue
+		}
+		overlaps := false
+		for i := off; i < off+size; i++ {
+			overlaps = overlaps || d.code[i]
+		}
+		if overlaps {
+			// jumping into the middle of an instruction, most likely a wrong
+			// guess, so the instruction already traced wins
+			continue
+		}
+		d.start[off] = true
+		for i := off; i < off+size; i++ {
+			d.code[i] = true
+		}
+
+		next := addr + size
+		switch in.Op {
+		case chip8.OpJP:
+			d.labels[in.Addr] = true
+			work = append(work, int(in.Addr))
+		case chip8.OpJPV0:
+			// the target depends on V0, but it is usually a table of jumps
+			// starting at nnn
+			d.labels[in.Addr] = true
+			work = append(work, int(in.Addr))
+		case chip8.OpCALL:
+			d.labels[in.Addr] = true
+			work = append(work, next, int(in.Addr))
+		case chip8.OpRET, chip8.OpEXIT:
+		case chip8.OpSEVxB, chip8.OpSNEVxB, chip8.OpSEVxVy, chip8.OpSNEVxVy, chip8.OpSKP, chip8.OpSKNP:
+			work = append(work, next)
+			if skipped, ok := d.instructionAt(next); ok {
+				work = append(work, next+int(skipped.Size()))
+			}
+		case chip8.OpLDI, chip8.OpLDILong:
+			d.labels[in.Addr] = true
+			work = append(work, next)
+		default:
+			work = append(work, next)
+		}
+	}
+}
+
+// labeled reports whether addr gets a label in the source, which is when
+// the code refers to it and there is a line starting at it.
+func (d *disassembly) labeled(addr uint16) bool {
+	if !d.labels[addr] {
+		return false
+	}
+	off := int(addr) - chip8.ProgramStart
+	if off == len(d.rom) {
+		return true
+	}
+	return off >= 0 && off < len(d.rom) && (d.start[off] || !d.code[off])
+}
+
+// source writes the traced ROM as assembly.
+func (d *disassembly) source() string {
+	var b strings.Builder
+	for off := 0; off < len(d.rom); {
+		addr := uint16(chip8.ProgramStart + off)
+		if d.labeled(addr) {
+			fmt.Fprintf(&b, "%s:\n", Label(addr))
+		}
+
+		if d.start[off] {
+			in := chip8.Decode(d.rom[off:])
+			fmt.Fprintf(&b, "\t%s\n", d.instruction(in))
+			off += int(in.Size())
+			continue
+		}
+
+		// data goes until the next line that needs to start on its own
+		end := off + 1
+		for end < len(d.rom) && end-off < dbPerLine && !d.code[end] && !d.labeled(uint16(chip8.ProgramStart+end)) {
+			end++
+		}
+		bytes := make([]string, end-off)
+		for i, c := range d.rom[off:end] {
+			bytes[i] = fmt.Sprintf("%02X", c)
+		}
+		fmt.Fprintf(&b, "\tdb %s\n", strings.Join(bytes, ", "))
+		off = end
+	}
+	if d.labeled(uint16(chip8.ProgramStart + len(d.rom))) {
+		fmt.Fprintf(&b, "%s:\n", Label(uint16(chip8.ProgramStart+len(d.rom))))
+	}
+	return b.String()
+}
+
+// instruction returns the assembly of in, with its address replaced by a
+// label if there is one.
+func (d *disassembly) instruction(in chip8.Instruction) string {
+	s := in.String()
+	switch in.Op {
+	case chip8.OpJP, chip8.OpJPV0, chip8.OpCALL, chip8.OpLDI, chip8.OpLDILong:
+		if d.labeled(in.Addr) {
+			s = strings.TrimSuffix(s, fmt.Sprintf("%04X", in.Addr)) + Label(in.Addr)
+		}
+	}
+	return s
+}
+
+// Label returns the name given to the label at addr.
+func Label(addr uint16) string {
+	return fmt.Sprintf("L%03X", addr)
+}
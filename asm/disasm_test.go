@@ -0,0 +1,38 @@
+package asm
+
+import (
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	rom := []byte{
+		0x00, 0xE0, // 200: CLS
+		0xA2, 0x10, // 202: LD I, 0210
+		0x30, 0x01, // 204: SE V0, 01
+		0xF0, 0x00, // 206: LD I, LONG 0211
+		0x02, 0x11,
+		0x22, 0x0E, // 20A: CALL 020E
+		0x12, 0x02, // 20C: JP 0202
+		0x00, 0xEE, // 20E: RET
+		0x3C, 0x42, // 210: sprite, labeled twice
+		0xFF, 0x00,
+		0x12, 0x05, // 214: JP 0205, never reached
+	}
+	want := `	CLS
+L202:
+	LD I, L210
+	SE V0, 01
+	LD I, LONG L211
+	CALL L20E
+	JP L202
+L20E:
+	RET
+L210:
+	db 3C
+L211:
+	db 42, FF, 00, 12, 05
+`
+	if got := Disassemble(rom); got != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,206 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// form is the syntax of an instruction: its mnemonic, the shape of its
+// operands and how they are encoded in its opcode. The operands are
+//
+//	Vx, Vy     registers, encoded in the x and y nibbles
+//	kk         a byte
+//	nnn        an address
+//	n          a nibble, encoded in the lowest nibble
+//	x          a nibble, encoded in the x nibble
+//	LONG nnnn  a 16 bit address, encoded in the word after the opcode
+//
+// and anything else must be written as is, like I or DT.
+type form struct {
+	mnemonic string
+	operands []string
+	opcode   uint16
+}
+
+var forms = parseForms(map[string]uint16{
+	"CLS":             0x00E0,
+	"RET":             0x00EE,
+	"SCD n":           0x00C0,
+	"SCU n":           0x00D0,
+	"SCR":             0x00FB,
+	"SCL":             0x00FC,
+	"EXIT":            0x00FD,
+	"LOW":             0x00FE,
+	"HIGH":            0x00FF,
+	"SYS nnn":         0x0000,
+	"JP nnn":          0x1000,
+	"CALL nnn":        0x2000,
+	"SE Vx, kk":       0x3000,
+	"SNE Vx, kk":      0x4000,
+	"SE Vx, Vy":       0x5000,
+	"SAVE Vx, Vy":     0x5002,
+	"LOAD Vx, Vy":     0x5003,
+	"LD Vx, kk":       0x6000,
+	"ADD Vx, kk":      0x7000,
+	"LD Vx, Vy":       0x8000,
+	"OR Vx, Vy":       0x8001,
+	"AND Vx, Vy":      0x8002,
+	"XOR Vx, Vy":      0x8003,
+	"ADD Vx, Vy":      0x8004,
+	"SUB Vx, Vy":      0x8005,
+	"SHR Vx, Vy":      0x8006,
+	"SUBN Vx, Vy":     0x8007,
+	"SHL Vx, Vy":      0x800E,
+	"SNE Vx, Vy":      0x9000,
+	"LD I, nnn":       0xA000,
+	"JP V0, nnn":      0xB000,
+	"RND Vx, kk":      0xC000,
+	"DRW Vx, Vy, n":   0xD000,
+	"SKP Vx":          0xE09E,
+	"SKNP Vx":         0xE0A1,
+	"LD I, LONG nnnn": 0xF000,
+	"PLANE x":         0xF001,
+	"AUDIO":           0xF002,
+	"LD Vx, DT":       0xF007,
+	"LD Vx, K":        0xF00A,
+	"LD DT, Vx":       0xF015,
+	"LD ST, Vx":       0xF018,
+	"ADD I, Vx":       0xF01E,
+	"LD F, Vx":        0xF029,
+	"LD HF, Vx":       0xF030,
+	"LD B, Vx":        0xF033,
+	"PITCH Vx":        0xF03A,
+	"LD [I], Vx":      0xF055,
+	"LD Vx, [I]":      0xF065,
+	"LD R, Vx":        0xF075,
+	"LD Vx, R":        0xF085,
+})
+
+func parseForms(syntax map[string]uint16) map[string][]*form {
+	forms := map[string][]*form{}
+	for s, opcode := range syntax {
+		mnemonic, operands, _ := strings.Cut(s, " ")
+		f := &form{mnemonic: mnemonic, opcode: opcode}
+		if operands != "" {
+			f.operands = strings.Split(operands, ", ")
+		}
+		forms[mnemonic] = append(forms[mnemonic], f)
+	}
+	return forms
+}
+
+func (f *form) String() string {
+	return strings.TrimSpace(f.mnemonic + " " + strings.Join(f.operands, ", "))
+}
+
+func (f *form) size() int {
+	if f.opcode == 0xF000 {
+		return 4
+	}
+	return 2
+}
+
+// isValue reports whether spec is an operand taking a number.
+func isValue(spec string) bool {
+	switch spec {
+	case "kk", "nnn", "n", "x":
+		return true
+	}
+	return false
+}
+
+// fits reports whether the operands have the shape of the form, and how
+// many of them are written as is. Numbers are checked later, when all the
+// labels are known.
+func (f *form) fits(operands []string) (bool, int) {
+	if len(operands) != len(f.operands) {
+		return false, 0
+	}
+	literals := 0
+	for i, spec := range f.operands {
+		op := operands[i]
+		switch {
+		case spec == "Vx" || spec == "Vy":
+			if !isRegister(op) {
+				return false, 0
+			}
+		case spec == "LONG nnnn":
+			if len(op) < 5 || !strings.EqualFold(op[:5], "LONG ") {
+				return false, 0
+			}
+		case isValue(spec):
+			if op == "" || isRegister(op) || len(op) >= 5 && strings.EqualFold(op[:5], "LONG ") {
+				return false, 0
+			}
+		default:
+			if !strings.EqualFold(op, spec) {
+				return false, 0
+			}
+			literals++
+		}
+	}
+	return true, literals
+}
+
+// match returns the form of an instruction. When more than one fits, like
+// LD Vx, kk and LD Vx, K for "LD V0, K", the one with more operands written
+// as is wins.
+func match(mnemonic string, operands []string) (*form, error) {
+	candidates, ok := forms[mnemonic]
+	if !ok {
+		return nil, fmt.Errorf("unknown instruction %s", mnemonic)
+	}
+
+	var best *form
+	bestLiterals := -1
+	for _, f := range candidates {
+		if ok, literals := f.fits(operands); ok && literals > bestLiterals {
+			best, bestLiterals = f, literals
+		}
+	}
+	if best == nil {
+		var want []string
+		for _, f := range candidates {
+			want = append(want, f.String())
+		}
+		return nil, fmt.Errorf("invalid operands for %s, want one of: %s", mnemonic, strings.Join(want, " | "))
+	}
+	return best, nil
+}
+
+// encode returns the bytes of the instruction.
+func (f *form) encode(a *assembler, operands []string) ([]byte, error) {
+	op := f.opcode
+	var long []byte
+	for i, spec := range f.operands {
+		var v int
+		var err error
+		switch spec {
+		case "Vx":
+			x, _ := register(operands[i])
+			op |= x << 8
+		case "Vy":
+			y, _ := register(operands[i])
+			op |= y << 4
+		case "kk":
+			v, err = a.value(operands[i], 0xFF)
+			op |= uint16(v)
+		case "nnn":
+			v, err = a.value(operands[i], 0xFFF)
+			op |= uint16(v)
+		case "n":
+			v, err = a.value(operands[i], 0xF)
+			op |= uint16(v)
+		case "x":
+			v, err = a.value(operands[i], 0xF)
+			op |= uint16(v) << 8
+		case "LONG nnnn":
+			v, err = a.value(operands[i][5:], 0xFFFF)
+			long = []byte{byte(v >> 8), byte(v)}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append([]byte{byte(op >> 8), byte(op)}, long...), nil
+}
@@ -0,0 +1,123 @@
+// Package audio turns the CHIP-8 sound timer into PCM audio.
+package audio
+
+import (
+	"math"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// SampleRate is the rate of the samples produced by Tone, in Hz.
+const SampleRate = 44100
+
+// DefaultFrequency is the pitch of the tone, in Hz.
+const DefaultFrequency = 440
+
+// samplesPerTick is how many samples cover one timer tick.
+const samplesPerTick = SampleRate / chip8.TimerHz
+
+// amplitude of the square wave, loud enough without clipping when mixed.
+const amplitude = 8000
+
+// Sink consumes mono 16-bit PCM samples at SampleRate.
+type Sink interface {
+	WriteSamples(samples []int16) error
+}
+
+// Tone is a chip8.Beeper that writes a square wave to a Sink while the sound
+// timer is active and silence otherwise, one tick worth of samples per call
+// to Beep. Once the program sets an XO-CHIP audio pattern, the pattern is
+// played instead of the square wave.
+type Tone struct {
+	sink  Sink
+	freq  float64
+	phase float64
+	buf   []int16
+	err   error
+
+	pattern    [16]uint8
+	hasPattern bool
+	bitRate    float64
+}
+
+// NewTone returns a Tone of frequency freq writing to sink.
+func NewTone(sink Sink, freq float64) *Tone {
+	return &Tone{
+		sink: sink,
+		freq: freq,
+		buf:  make([]int16, samplesPerTick),
+	}
+}
+
+func (t *Tone) Beep(on bool) {
+	if t.err != nil {
+		return
+	}
+	for i := range t.buf {
+		t.buf[i] = 0
+		if on {
+			t.buf[i] = amplitude
+			if !t.high() {
+				t.buf[i] = -amplitude
+			}
+		}
+		// the phase keeps advancing in silence so consecutive beeps don't
+		// click
+		t.phase += t.cycleRate() / SampleRate
+		t.phase -= float64(int(t.phase))
+	}
+	t.err = t.sink.WriteSamples(t.buf)
+}
+
+// Pattern implements chip8.PatternBeeper.
+func (t *Tone) Pattern(pattern [16]uint8, pitch uint8) {
+	t.pattern = pattern
+	t.hasPattern = true
+	t.bitRate = 4000 * math.Pow(2, (float64(pitch)-64)/48)
+}
+
+// cycleRate is how many times per second the phase goes from 0 to 1: one
+// period of the square wave or one loop over the 128 bits of the pattern.
+func (t *Tone) cycleRate() float64 {
+	if t.hasPattern {
+		return t.bitRate / 128
+	}
+	return t.freq
+}
+
+// high reports whether the wave is at its high level at the current phase.
+func (t *Tone) high() bool {
+	if t.hasPattern {
+		bit := int(t.phase * 128)
+		return t.pattern[bit/8]>>(7-bit%8)&1 == 1
+	}
+	return t.phase < 0.5
+}
+
+// Err returns the first error returned by the sink. Once the sink fails
+// nothing else is written to it.
+func (t *Tone) Err() error {
+	return t.err
+}
+
+// Multi returns a chip8.Beeper that forwards every call to each of beepers.
+// It is a chip8.PatternBeeper, forwarding patterns to the beepers that are.
+func Multi(beepers ...chip8.Beeper) chip8.Beeper {
+	return multi(beepers)
+}
+
+type multi []chip8.Beeper
+
+func (m multi) Beep(on bool) {
+	for _, b := range m {
+		b.Beep(on)
+	}
+}
+
+func (m multi) Pattern(pattern [16]uint8, pitch uint8) {
+	for _, b := range m {
+		if pb, ok := b.(chip8.PatternBeeper); ok {
+			pb.Pattern(pattern, pitch)
+		}
+	}
+}
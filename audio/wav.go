@@ -0,0 +1,70 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const wavHeaderSize = 44
+
+// WAVWriter is a Sink that writes a mono 16-bit PCM WAV file. The sizes in
+// the header are only filled in by Close.
+type WAVWriter struct {
+	w    io.WriteSeeker
+	size uint32
+}
+
+// NewWAVWriter writes the WAV header to w and returns a writer for the
+// samples.
+func NewWAVWriter(w io.WriteSeeker) (*WAVWriter, error) {
+	ww := &WAVWriter{w: w}
+	err := ww.writeHeader()
+	if err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+func (ww *WAVWriter) writeHeader() error {
+	const channels, bits = 1, 16
+	h := make([]byte, 0, wavHeaderSize)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, 36+ww.size)
+	h = append(h, "WAVE"...)
+	h = append(h, "fmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, 1) // PCM
+	h = binary.LittleEndian.AppendUint16(h, channels)
+	h = binary.LittleEndian.AppendUint32(h, SampleRate)
+	h = binary.LittleEndian.AppendUint32(h, SampleRate*channels*bits/8)
+	h = binary.LittleEndian.AppendUint16(h, channels*bits/8)
+	h = binary.LittleEndian.AppendUint16(h, bits)
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, ww.size)
+	_, err := ww.w.Write(h)
+	return err
+}
+
+func (ww *WAVWriter) WriteSamples(samples []int16) error {
+	err := binary.Write(ww.w, binary.LittleEndian, samples)
+	if err != nil {
+		return err
+	}
+	ww.size += uint32(len(samples) * 2)
+	return nil
+}
+
+// Close fills in the sizes in the header. It does not close the underlying
+// writer.
+func (ww *WAVWriter) Close() error {
+	_, err := ww.w.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	err = ww.writeHeader()
+	if err != nil {
+		return err
+	}
+	_, err = ww.w.Seek(0, io.SeekEnd)
+	return err
+}
@@ -0,0 +1,119 @@
+package audio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func TestWAVWriter(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ww, err := NewWAVWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tone := NewTone(ww, DefaultFrequency)
+	tone.Beep(true)
+	tone.Beep(false)
+	if tone.Err() != nil {
+		t.Fatal(tone.Err())
+	}
+	err = ww.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataSize := 2 * samplesPerTick * 2
+	if len(b) != wavHeaderSize+dataSize {
+		t.Fatalf("want: %d bytes, got: %d", wavHeaderSize+dataSize, len(b))
+	}
+	if string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" || string(b[36:40]) != "data" {
+		t.Fatalf("bad header: %q", b[:wavHeaderSize])
+	}
+	if got := binary.LittleEndian.Uint32(b[40:44]); got != uint32(dataSize) {
+		t.Fatalf("want: data size %d, got: %d", dataSize, got)
+	}
+
+	samples := make([]int16, 2*samplesPerTick)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(b[wavHeaderSize+2*i:]))
+	}
+	high, low := 0, 0
+	for _, s := range samples[:samplesPerTick] {
+		switch s {
+		case amplitude:
+			high++
+		case -amplitude:
+			low++
+		default:
+			t.Fatalf("want: square wave while on, got sample %d", s)
+		}
+	}
+	if high == 0 || low == 0 {
+		t.Fatalf("want: both half periods, got: %d high, %d low", high, low)
+	}
+	for _, s := range samples[samplesPerTick:] {
+		if s != 0 {
+			t.Fatalf("want: silence while off, got sample %d", s)
+		}
+	}
+}
+
+type sampleBuffer []int16
+
+func (b *sampleBuffer) WriteSamples(samples []int16) error {
+	*b = append(*b, samples...)
+	return nil
+}
+
+func TestTone_Pattern(t *testing.T) {
+	var buf sampleBuffer
+	tone := NewTone(&buf, DefaultFrequency)
+
+	// at pitch 64 the pattern plays at 4000 bits per second, so an all high
+	// pattern stays high for the whole tick
+	var pattern [16]uint8
+	for i := range pattern {
+		pattern[i] = 0xFF
+	}
+	tone.Pattern(pattern, 64)
+	tone.Beep(true)
+	for _, s := range buf {
+		if s != amplitude {
+			t.Fatalf("want: constant high level, got sample %d", s)
+		}
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var buf sampleBuffer
+	tone := NewTone(&buf, DefaultFrequency)
+
+	b, ok := Multi(tone).(chip8.PatternBeeper)
+	if !ok {
+		t.Fatal("want: a PatternBeeper, got: a Beeper")
+	}
+	var pattern [16]uint8
+	for i := range pattern {
+		pattern[i] = 0xFF
+	}
+	b.Pattern(pattern, 64)
+	b.Beep(true)
+	for _, s := range buf {
+		if s != amplitude {
+			t.Fatalf("want: the pattern forwarded to the tone, got sample %d", s)
+		}
+	}
+}
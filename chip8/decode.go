@@ -0,0 +1,319 @@
+package chip8
+
+import "fmt"
+
+// Op identifies an instruction, independently of its operands.
+type Op uint8
+
+const (
+	OpInvalid Op = iota
+	OpCLS        // 00E0
+	OpRET        // 00EE
+	OpSCD        // 00Cn
+	OpSCU        // 00Dn
+	OpSCR        // 00FB
+	OpSCL        // 00FC
+	OpEXIT       // 00FD
+	OpLOW        // 00FE
+	OpHIGH       // 00FF
+	OpSYS        // 0nnn
+	OpJP         // 1nnn
+	OpCALL       // 2nnn
+	OpSEVxB      // 3xkk
+	OpSNEVxB     // 4xkk
+	OpSEVxVy     // 5xy0
+	OpSAVE       // 5xy2
+	OpLOAD       // 5xy3
+	OpLDVxB      // 6xkk
+	OpADDVxB     // 7xkk
+	OpLDVxVy     // 8xy0
+	OpOR         // 8xy1
+	OpAND        // 8xy2
+	OpXOR        // 8xy3
+	OpADDVxVy    // 8xy4
+	OpSUB        // 8xy5
+	OpSHR        // 8xy6
+	OpSUBN       // 8xy7
+	OpSHL        // 8xyE
+	OpSNEVxVy    // 9xy0
+	OpLDI        // Annn
+	OpJPV0       // Bnnn
+	OpRND        // Cxkk
+	OpDRW        // Dxyn
+	OpSKP        // Ex9E
+	OpSKNP       // ExA1
+	OpLDILong    // F000 nnnn
+	OpPLANE      // Fn01
+	OpAUDIO      // F002
+	OpLDVxDT     // Fx07
+	OpLDVxK      // Fx0A
+	OpLDDTVx     // Fx15
+	OpLDSTVx     // Fx18
+	OpADDIVx     // Fx1E
+	OpLDFVx      // Fx29
+	OpLDHFVx     // Fx30
+	OpLDBVx      // Fx33
+	OpPITCH      // Fx3A
+	OpLDIVx      // Fx55
+	OpLDVxI      // Fx65
+	OpLDRVx      // Fx75
+	OpLDVxR      // Fx85
+
+	numOps
+)
+
+// Instruction is a decoded opcode. All the operand fields are extracted from
+// every opcode, and it's up to Op which of them are meaningful.
+type Instruction struct {
+	Op     Op
+	Opcode uint16
+
+	X    uint8  // -x--
+	Y    uint8  // --y-
+	N    uint8  // ---n
+	B    uint8  // --kk
+	Addr uint16 // -nnn, or the word following F000
+}
+
+// Size returns the length of the instruction in bytes.
+func (in Instruction) Size() uint16 {
+	if in.Op == OpLDILong {
+		return 4
+	}
+	return 2
+}
+
+// decoders decode opcodes by their highest nibble.
+var decoders = [16]func(op uint16) Op{
+	0x0: decode0,
+	0x1: func(uint16) Op { return OpJP },
+	0x2: func(uint16) Op { return OpCALL },
+	0x3: func(uint16) Op { return OpSEVxB },
+	0x4: func(uint16) Op { return OpSNEVxB },
+	0x5: decode5,
+	0x6: func(uint16) Op { return OpLDVxB },
+	0x7: func(uint16) Op { return OpADDVxB },
+	0x8: decode8,
+	0x9: decode9,
+	0xA: func(uint16) Op { return OpLDI },
+	0xB: func(uint16) Op { return OpJPV0 },
+	0xC: func(uint16) Op { return OpRND },
+	0xD: func(uint16) Op { return OpDRW },
+	0xE: decodeE,
+	0xF: decodeF,
+}
+
+func decode0(op uint16) Op {
+	switch {
+	case op == 0x00E0:
+		return OpCLS
+	case op == 0x00EE:
+		return OpRET
+	case op&0xFFF0 == 0x00C0:
+		return OpSCD
+	case op&0xFFF0 == 0x00D0:
+		return OpSCU
+	case op == 0x00FB:
+		return OpSCR
+	case op == 0x00FC:
+		return OpSCL
+	case op == 0x00FD:
+		return OpEXIT
+	case op == 0x00FE:
+		return OpLOW
+	case op == 0x00FF:
+		return OpHIGH
+	}
+	return OpSYS
+}
+
+var ops5 = [16]Op{0x0: OpSEVxVy, 0x2: OpSAVE, 0x3: OpLOAD}
+
+func decode5(op uint16) Op {
+	return ops5[op&0xF]
+}
+
+var ops8 = [16]Op{
+	0x0: OpLDVxVy,
+	0x1: OpOR,
+	0x2: OpAND,
+	0x3: OpXOR,
+	0x4: OpADDVxVy,
+	0x5: OpSUB,
+	0x6: OpSHR,
+	0x7: OpSUBN,
+	0xE: OpSHL,
+}
+
+func decode8(op uint16) Op {
+	return ops8[op&0xF]
+}
+
+func decode9(op uint16) Op {
+	if op&0xF == 0 {
+		return OpSNEVxVy
+	}
+	return OpInvalid
+}
+
+func decodeE(op uint16) Op {
+	switch op & 0xFF {
+	case 0x9E:
+		return OpSKP
+	case 0xA1:
+		return OpSKNP
+	}
+	return OpInvalid
+}
+
+var opsF = [256]Op{
+	0x01: OpPLANE,
+	0x07: OpLDVxDT,
+	0x0A: OpLDVxK,
+	0x15: OpLDDTVx,
+	0x18: OpLDSTVx,
+	0x1E: OpADDIVx,
+	0x29: OpLDFVx,
+	0x30: OpLDHFVx,
+	0x33: OpLDBVx,
+	0x3A: OpPITCH,
+	0x55: OpLDIVx,
+	0x65: OpLDVxI,
+	0x75: OpLDRVx,
+	0x85: OpLDVxR,
+}
+
+func decodeF(op uint16) Op {
+	switch op {
+	case 0xF000:
+		return OpLDILong
+	case 0xF002:
+		return OpAUDIO
+	}
+	return opsF[op&0xFF]
+}
+
+// Decode decodes the instruction at the start of code. Only F000 nnnn
+// needs more than 2 bytes. If code is too short the instruction is invalid.
+func Decode(code []byte) Instruction {
+	if len(code) < 2 {
+		in := Instruction{Op: OpInvalid}
+		if len(code) == 1 {
+			in.Opcode = uint16(code[0]) << 8
+		}
+		return in
+	}
+
+	op := uint16(code[0])<<8 | uint16(code[1])
+	in := Instruction{
+		Op:     decoders[op>>12](op),
+		Opcode: op,
+		X:      uint8(op >> 8 & 0xF),
+		Y:      uint8(op >> 4 & 0xF),
+		N:      uint8(op & 0xF),
+		B:      uint8(op & 0xFF),
+		Addr:   op & 0xFFF,
+	}
+	if in.Op == OpLDILong {
+		if len(code) < 4 {
+			in.Op = OpInvalid
+			return in
+		}
+		in.Addr = uint16(code[2])<<8 | uint16(code[3])
+	}
+	return in
+}
+
+// operands are the shapes of the operands of the instructions, telling
+// String which fields to format.
+type operands uint8
+
+const (
+	operandsNone operands = iota
+	operandsAddr
+	operandsX
+	operandsXB
+	operandsXY
+	operandsXYN
+	operandsN
+)
+
+var syntax = [numOps]struct {
+	format   string
+	operands operands
+}{
+	OpCLS:     {"CLS", operandsNone},
+	OpRET:     {"RET", operandsNone},
+	OpSCD:     {"SCD %X", operandsN},
+	OpSCU:     {"SCU %X", operandsN},
+	OpSCR:     {"SCR", operandsNone},
+	OpSCL:     {"SCL", operandsNone},
+	OpEXIT:    {"EXIT", operandsNone},
+	OpLOW:     {"LOW", operandsNone},
+	OpHIGH:    {"HIGH", operandsNone},
+	OpSYS:     {"SYS %04X", operandsAddr},
+	OpJP:      {"JP %04X", operandsAddr},
+	OpCALL:    {"CALL %04X", operandsAddr},
+	OpSEVxB:   {"SE V%X, %02X", operandsXB},
+	OpSNEVxB:  {"SNE V%X, %02X", operandsXB},
+	OpSEVxVy:  {"SE V%X, V%X", operandsXY},
+	OpSAVE:    {"SAVE V%X, V%X", operandsXY},
+	OpLOAD:    {"LOAD V%X, V%X", operandsXY},
+	OpLDVxB:   {"LD V%X, %02X", operandsXB},
+	OpADDVxB:  {"ADD V%X, %02X", operandsXB},
+	OpLDVxVy:  {"LD V%X, V%X", operandsXY},
+	OpOR:      {"OR V%X, V%X", operandsXY},
+	OpAND:     {"AND V%X, V%X", operandsXY},
+	OpXOR:     {"XOR V%X, V%X", operandsXY},
+	OpADDVxVy: {"ADD V%X, V%X", operandsXY},
+	OpSUB:     {"SUB V%X, V%X", operandsXY},
+	OpSHR:     {"SHR V%X, V%X", operandsXY},
+	OpSUBN:    {"SUBN V%X, V%X", operandsXY},
+	OpSHL:     {"SHL V%X, V%X", operandsXY},
+	OpSNEVxVy: {"SNE V%X, V%X", operandsXY},
+	OpLDI:     {"LD I, %04X", operandsAddr},
+	OpJPV0:    {"JP V0, %04X", operandsAddr},
+	OpRND:     {"RND V%X, %02X", operandsXB},
+	OpDRW:     {"DRW V%X, V%X, %X", operandsXYN},
+	OpSKP:     {"SKP V%X", operandsX},
+	OpSKNP:    {"SKNP V%X", operandsX},
+	OpLDILong: {"LD I, LONG %04X", operandsAddr},
+	OpPLANE:   {"PLANE %X", operandsX},
+	OpAUDIO:   {"AUDIO", operandsNone},
+	OpLDVxDT:  {"LD V%X, DT", operandsX},
+	OpLDVxK:   {"LD V%X, K", operandsX},
+	OpLDDTVx:  {"LD DT, V%X", operandsX},
+	OpLDSTVx:  {"LD ST, V%X", operandsX},
+	OpADDIVx:  {"ADD I, V%X", operandsX},
+	OpLDFVx:   {"LD F, V%X", operandsX},
+	OpLDHFVx:  {"LD HF, V%X", operandsX},
+	OpLDBVx:   {"LD B, V%X", operandsX},
+	OpPITCH:   {"PITCH V%X", operandsX},
+	OpLDIVx:   {"LD [I], V%X", operandsX},
+	OpLDVxI:   {"LD V%X, [I]", operandsX},
+	OpLDRVx:   {"LD R, V%X", operandsX},
+	OpLDVxR:   {"LD V%X, R", operandsX},
+}
+
+// String returns the assembly of the instruction, or "" if it is invalid.
+func (in Instruction) String() string {
+	if in.Op == OpInvalid || in.Op >= numOps {
+		return ""
+	}
+	s := syntax[in.Op]
+	switch s.operands {
+	case operandsAddr:
+		return fmt.Sprintf(s.format, in.Addr)
+	case operandsX:
+		return fmt.Sprintf(s.format, in.X)
+	case operandsXB:
+		return fmt.Sprintf(s.format, in.X, in.B)
+	case operandsXY:
+		return fmt.Sprintf(s.format, in.X, in.Y)
+	case operandsXYN:
+		return fmt.Sprintf(s.format, in.X, in.Y, in.N)
+	case operandsN:
+		return fmt.Sprintf(s.format, in.N)
+	}
+	return s.format
+}
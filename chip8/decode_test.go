@@ -0,0 +1,76 @@
+package chip8
+
+import "testing"
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		code []byte
+		op   Op
+		asm  string
+	}{
+		{[]byte{0x00, 0xE0}, OpCLS, "CLS"},
+		{[]byte{0x00, 0xC5}, OpSCD, "SCD 5"},
+		{[]byte{0x01, 0x23}, OpSYS, "SYS 0123"},
+		{[]byte{0x5A, 0xB0}, OpSEVxVy, "SE VA, VB"},
+		{[]byte{0x5A, 0xB1}, OpInvalid, ""},
+		{[]byte{0x81, 0x26}, OpSHR, "SHR V1, V2"},
+		{[]byte{0x81, 0x28}, OpInvalid, ""},
+		{[]byte{0xA3, 0x45}, OpLDI, "LD I, 0345"},
+		{[]byte{0xB3, 0x45}, OpJPV0, "JP V0, 0345"},
+		{[]byte{0xD1, 0x2F}, OpDRW, "DRW V1, V2, F"},
+		{[]byte{0xE3, 0x9E}, OpSKP, "SKP V3"},
+		{[]byte{0xE3, 0x9F}, OpInvalid, ""},
+		{[]byte{0xF0, 0x00, 0x12, 0x34}, OpLDILong, "LD I, LONG 1234"},
+		{[]byte{0xF0, 0x00, 0x12}, OpInvalid, ""},
+		{[]byte{0xF2, 0x01}, OpPLANE, "PLANE 2"},
+		{[]byte{0xF1, 0x02}, OpInvalid, ""},
+		{[]byte{0xF7, 0x65}, OpLDVxI, "LD V7, [I]"},
+		{[]byte{0xF7}, OpInvalid, ""},
+	}
+	for _, tt := range tests {
+		in := Decode(tt.code)
+		if in.Op != tt.op || in.String() != tt.asm {
+			t.Errorf("% X: want: %d %q, got: %d %q", tt.code, tt.op, tt.asm, in.Op, in.String())
+		}
+	}
+}
+
+func TestDecode_everyOpReachable(t *testing.T) {
+	seen := map[Op]bool{}
+	for op := 0; op <= 0xFFFF; op++ {
+		in := Decode([]byte{byte(op >> 8), byte(op), 0, 0})
+		if in.Op == OpInvalid {
+			continue
+		}
+		seen[in.Op] = true
+		if in.String() == "" {
+			t.Fatalf("%04X: no assembly for op %d", op, in.Op)
+		}
+	}
+	for op := Op(1); op < numOps; op++ {
+		if !seen[op] {
+			t.Errorf("op %d is never decoded", op)
+		}
+		if handlers[op] == nil {
+			t.Errorf("op %d has no handler", op)
+		}
+	}
+}
+
+func TestMachine_Step_allocs(t *testing.T) {
+	m := New(Config{})
+	err := m.LoadROM([]byte{
+		0x60, 0x01, // LD V0, 01
+		0x80, 0x04, // ADD V0, V0
+		0xA3, 0x00, // LD I, 0300
+		0xD0, 0x05, // DRW V0, V0, 5
+		0x12, 0x00, // JP 0200
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(100, func() { m.Step() })
+	if allocs != 0 {
+		t.Fatalf("want: no allocations per step, got: %v", allocs)
+	}
+}
@@ -0,0 +1,30 @@
+package chip8
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors raised by Step, wrapped in an *Error.
+var (
+	ErrUnknownOpcode     = errors.New("unknown opcode")
+	ErrStackOverflow     = errors.New("stack overflow")
+	ErrStackUnderflow    = errors.New("stack underflow")
+	ErrMemoryOutOfBounds = errors.New("memory access out of bounds")
+)
+
+// Error is an error raised while executing an instruction. The machine is
+// left as it was before the instruction, with PC pointing to it.
+type Error struct {
+	Err    error
+	PC     uint16
+	Opcode uint16
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: opcode %04X at %04X", e.Err, e.Opcode, e.PC)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,317 @@
+package chip8
+
+// where fontSet and bigFontSet are loaded in memory
+const (
+	fontAddr    = 0x000
+	bigFontAddr = 0x050
+)
+
+// if you blur your vision, you'll see it a little better
+var fontSet = []byte{
+	// 0
+	0b11110000,
+	0b10010000,
+	0b10010000,
+	0b10010000,
+	0b11110000,
+
+	// 1
+	0b00100000,
+	0b01100000,
+	0b00100000,
+	0b00100000,
+	0b01110000,
+
+	// 2
+	0b11110000,
+	0b00010000,
+	0b11110000,
+	0b10000000,
+	0b11110000,
+
+	// 3
+	0b11110000,
+	0b00010000,
+	0b11110000,
+	0b00010000,
+	0b11110000,
+
+	// 4
+	0b10010000,
+	0b10010000,
+	0b11110000,
+	0b00010000,
+	0b00010000,
+
+	// 5
+	0b11110000,
+	0b10000000,
+	0b11110000,
+	0b00010000,
+	0b11110000,
+
+	// 6
+	0b11110000,
+	0b10000000,
+	0b11110000,
+	0b10010000,
+	0b11110000,
+
+	// 7
+	0b11110000,
+	0b00010000,
+	0b00100000,
+	0b01000000,
+	0b01000000,
+
+	// 8
+	0b11110000,
+	0b10010000,
+	0b11110000,
+	0b10010000,
+	0b11110000,
+
+	// 9
+	0b11110000,
+	0b10010000,
+	0b11110000,
+	0b00010000,
+	0b11110000,
+
+	// A
+	0b11110000,
+	0b10010000,
+	0b11110000,
+	0b10010000,
+	0b10010000,
+
+	// B
+	0b11100000,
+	0b10010000,
+	0b11100000,
+	0b10010000,
+	0b11100000,
+
+	// C
+	0b11110000,
+	0b10000000,
+	0b10000000,
+	0b10000000,
+	0b11110000,
+
+	// D
+	0b11100000,
+	0b10010000,
+	0b10010000,
+	0b10010000,
+	0b11100000,
+
+	// E
+	0b11110000,
+	0b10000000,
+	0b11110000,
+	0b10000000,
+	0b11110000,
+
+	// F
+	0b11110000,
+	0b10000000,
+	0b11110000,
+	0b10000000,
+	0b10000000,
+}
+
+// the SUPER-CHIP 8x10 font, loaded right after fontSet
+var bigFontSet = []byte{
+	// 0
+	0b00111100,
+	0b01111110,
+	0b11100111,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11100111,
+	0b01111110,
+	0b00111100,
+
+	// 1
+	0b00011000,
+	0b00111000,
+	0b01011000,
+	0b00011000,
+	0b00011000,
+	0b00011000,
+	0b00011000,
+	0b00011000,
+	0b00011000,
+	0b00111100,
+
+	// 2
+	0b00111110,
+	0b01111111,
+	0b11000011,
+	0b00000110,
+	0b00001100,
+	0b00011000,
+	0b00110000,
+	0b01100000,
+	0b11111111,
+	0b11111111,
+
+	// 3
+	0b00111100,
+	0b01111110,
+	0b11000011,
+	0b00000011,
+	0b00001110,
+	0b00001110,
+	0b00000011,
+	0b11000011,
+	0b01111110,
+	0b00111100,
+
+	// 4
+	0b00000110,
+	0b00001110,
+	0b00011110,
+	0b00110110,
+	0b01100110,
+	0b11000110,
+	0b11111111,
+	0b11111111,
+	0b00000110,
+	0b00000110,
+
+	// 5
+	0b11111111,
+	0b11111111,
+	0b11000000,
+	0b11000000,
+	0b11111100,
+	0b11111110,
+	0b00000011,
+	0b11000011,
+	0b01111110,
+	0b00111100,
+
+	// 6
+	0b00111110,
+	0b01111100,
+	0b11000000,
+	0b11000000,
+	0b11111100,
+	0b11111110,
+	0b11000011,
+	0b11000011,
+	0b01111110,
+	0b00111100,
+
+	// 7
+	0b11111111,
+	0b11111111,
+	0b00000011,
+	0b00000110,
+	0b00001100,
+	0b00011000,
+	0b00110000,
+	0b01100000,
+	0b01100000,
+	0b01100000,
+
+	// 8
+	0b00111100,
+	0b01111110,
+	0b11000011,
+	0b11000011,
+	0b01111110,
+	0b01111110,
+	0b11000011,
+	0b11000011,
+	0b01111110,
+	0b00111100,
+
+	// 9
+	0b00111100,
+	0b01111110,
+	0b11000011,
+	0b11000011,
+	0b01111111,
+	0b00111111,
+	0b00000011,
+	0b00000011,
+	0b00111110,
+	0b01111100,
+
+	// A
+	0b01111110,
+	0b11111111,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11111111,
+	0b11111111,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+
+	// B
+	0b11111100,
+	0b11111100,
+	0b11000011,
+	0b11000011,
+	0b11111100,
+	0b11111100,
+	0b11000011,
+	0b11000011,
+	0b11111100,
+	0b11111100,
+
+	// C
+	0b00111100,
+	0b11111111,
+	0b11000011,
+	0b11000000,
+	0b11000000,
+	0b11000000,
+	0b11000000,
+	0b11000011,
+	0b11111111,
+	0b00111100,
+
+	// D
+	0b11111100,
+	0b11111110,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11000011,
+	0b11111110,
+	0b11111100,
+
+	// E
+	0b11111111,
+	0b11111111,
+	0b11000000,
+	0b11000000,
+	0b11111111,
+	0b11111111,
+	0b11000000,
+	0b11000000,
+	0b11111111,
+	0b11111111,
+
+	// F
+	0b11111111,
+	0b11111111,
+	0b11000000,
+	0b11000000,
+	0b11111111,
+	0b11111111,
+	0b11000000,
+	0b11000000,
+	0b11000000,
+	0b11000000,
+}
@@ -0,0 +1,194 @@
+package chip8
+
+// History records the instructions executed by a machine, so they can be
+// undone. It keeps the changes made by the last instructions, up to the
+// size it was made with, overwriting the oldest ones as new instructions
+// are executed.
+//
+// Only the changes made by Step are recorded: changes made with methods
+// like Poke or SetV stay when instructions are undone.
+type History struct {
+	// ring buffer of records, the oldest at start
+	records []record
+	start   int
+	n       int
+
+	// screen after the last instruction, to find the rows it changed
+	screen [64][128]uint8
+}
+
+// record holds the changes made by an instruction, enough to undo it.
+type record struct {
+	before registers
+
+	// old values of the memory written, in the order it was written
+	writes []memoryWrite
+
+	// old values of the screen rows changed
+	rows []screenRow
+}
+
+type memoryWrite struct {
+	addr uint16
+	n    uint8
+	old  [16]uint8
+}
+
+type screenRow struct {
+	y   uint8
+	old [128]uint8
+}
+
+// registers is the state of the machine other than memory and screen.
+type registers struct {
+	v          [16]uint8
+	i          uint16
+	dt, st     uint8
+	pc, sp     uint16
+	stack      [16]uint16
+	plane      uint8
+	pattern    [16]uint8
+	pitch      uint8
+	hires      bool
+	rpl        [16]uint8
+	exited     bool
+	heldKey    uint8
+	holdingKey bool
+	drew       bool
+	steps      int
+	rng        uint64
+}
+
+// NewHistory returns a history that keeps the last size instructions.
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+	return &History{records: make([]record, size)}
+}
+
+// Len returns the number of instructions that can be undone.
+func (h *History) Len() int { return h.n }
+
+// clear forgets the recorded instructions, starting again from the current
+// state of m.
+func (h *History) clear(m *Machine) {
+	h.start, h.n = 0, 0
+	h.screen = m.screen
+}
+
+// begin records the state of m before executing an instruction.
+func (h *History) begin(m *Machine) {
+	i := (h.start + h.n) % len(h.records)
+	if h.n == len(h.records) {
+		h.start = (h.start + 1) % len(h.records)
+	} else {
+		h.n++
+	}
+	r := &h.records[i]
+	r.before = m.registers()
+	r.writes = r.writes[:0]
+	r.rows = r.rows[:0]
+}
+
+// write records the memory about to be written by the instruction.
+func (h *History) write(m *Machine, addr uint16, n int) {
+	w := memoryWrite{addr: addr, n: uint8(n)}
+	copy(w.old[:n], m.ram[addr:])
+	r := h.last()
+	r.writes = append(r.writes, w)
+}
+
+// end records the screen rows changed by the instruction, or drops the
+// record if the instruction failed.
+func (h *History) end(m *Machine, failed bool) {
+	if failed {
+		h.n--
+		return
+	}
+	r := h.last()
+	for y := range m.screen {
+		if m.screen[y] != h.screen[y] {
+			r.rows = append(r.rows, screenRow{y: uint8(y), old: h.screen[y]})
+			h.screen[y] = m.screen[y]
+		}
+	}
+}
+
+func (h *History) last() *record {
+	return &h.records[(h.start+h.n-1)%len(h.records)]
+}
+
+// undo restores m to its state before the last instruction recorded,
+// returning the record undone.
+func (h *History) undo(m *Machine) *record {
+	r := h.last()
+	h.n--
+	m.setRegisters(r.before)
+	for i := len(r.writes) - 1; i >= 0; i-- {
+		w := r.writes[i]
+		copy(m.ram[w.addr:], w.old[:w.n])
+	}
+	for _, row := range r.rows {
+		m.screen[row.y] = row.old
+		h.screen[row.y] = row.old
+	}
+	return r
+}
+
+// SetHistory makes the machine record the instructions it executes in h,
+// so they can be undone with StepBack and Rewind. A nil h stops recording.
+func (m *Machine) SetHistory(h *History) {
+	m.history = h
+	if h != nil {
+		h.clear(m)
+	}
+}
+
+// StepBack undoes the last instruction executed, reporting whether there
+// was one in the history.
+func (m *Machine) StepBack() bool {
+	if m.history == nil || m.history.n == 0 {
+		return false
+	}
+	m.history.undo(m)
+	m.display.Draw(m.Screen())
+	m.updatePattern()
+	return true
+}
+
+// Rewind undoes the instructions of the last n frames, returning the
+// number of frames undone, which is less than n when the history doesn't
+// go back that far. Frames only partly executed, or only partly in the
+// history, count as one.
+func (m *Machine) Rewind(n int) int {
+	if m.history == nil || m.history.n == 0 {
+		return 0
+	}
+	frames := 0
+	for frames < n && m.history.n > 0 {
+		r := m.history.undo(m)
+		if r.before.steps == 0 || m.history.n == 0 {
+			frames++
+		}
+	}
+	m.display.Draw(m.Screen())
+	m.updatePattern()
+	return frames
+}
+
+func (m *Machine) registers() registers {
+	return registers{
+		v: m.v, i: m.i, dt: m.dt, st: m.st, pc: m.pc, sp: m.sp, stack: m.stack,
+		plane: m.plane, pattern: m.pattern, pitch: m.pitch, hires: m.hires, rpl: m.rpl,
+		exited: m.exited, heldKey: m.heldKey, holdingKey: m.holdingKey, drew: m.drew, steps: m.steps,
+		rng: m.rngState(),
+	}
+}
+
+func (m *Machine) setRegisters(r registers) {
+	m.v, m.i, m.dt, m.st, m.pc, m.sp, m.stack = r.v, r.i, r.dt, r.st, r.pc, r.sp, r.stack
+	m.plane, m.pattern, m.pitch, m.hires, m.rpl = r.plane, r.pattern, r.pitch, r.hires, r.rpl
+	m.exited, m.heldKey, m.holdingKey, m.drew, m.steps = r.exited, r.heldKey, r.holdingKey, r.drew, r.steps
+	m.setRNGState(r.rng)
+}
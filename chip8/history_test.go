@@ -0,0 +1,94 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMachine_StepBack(t *testing.T) {
+	d := &testDevices{}
+	m := New(Config{Display: d, IPF: 3})
+	err := m.LoadROM([]byte{
+		0xA3, 0x00, // 200: LD I, 0300
+		0x60, 0x7B, // 202: LD V0, 7B
+		0xF0, 0x33, // 204: LD B, V0
+		0xF0, 0x15, // 206: LD DT, V0
+		0xD0, 0x05, // 208: DRW V0, V0, 5
+		0x00, 0xFF, // 20A: HIGH
+		0x22, 0x10, // 20C: CALL 0210
+		0x12, 0x0E, // 20E: JP 020E
+		0x00, 0xE0, // 210: CLS
+		0x00, 0xEE, // 212: RET
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetHistory(NewHistory(100))
+
+	// the states before each instruction, ticking the timers as Frame does
+	var states [][]byte
+	for i := 0; i < 12; i++ {
+		state, _ := m.MarshalBinary()
+		states = append(states, state)
+		m.Step()
+		if m.FrameSteps() == m.IPF() {
+			m.Tick()
+		}
+	}
+
+	for i := len(states) - 1; i >= 0; i-- {
+		if !m.StepBack() {
+			t.Fatalf("want: step back to state %d, got: none", i)
+		}
+		got, _ := m.MarshalBinary()
+		if !bytes.Equal(got, states[i]) {
+			t.Fatalf("want: state %d restored, got: PC=%04X", i, m.PC())
+		}
+		if d.last != m.Screen() {
+			t.Fatalf("want: restored screen drawn at state %d, got: a different one", i)
+		}
+	}
+	if m.StepBack() {
+		t.Fatal("want: no more history, got: a step back")
+	}
+}
+
+func TestMachine_Rewind(t *testing.T) {
+	m := New(Config{IPF: 2})
+	err := m.LoadROM([]byte{
+		0x70, 0x01, // 200: ADD V0, 01
+		0x12, 0x00, // 202: JP 0200
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetHistory(NewHistory(4))
+
+	for i := 0; i < 4; i++ {
+		m.Frame()
+	}
+	m.Step()
+	if m.V(0) != 5 {
+		t.Fatalf("want: V0=05, got: %02X", m.V(0))
+	}
+
+	// the frame just started counts as one
+	if got := m.Rewind(2); got != 2 || m.V(0) != 3 || m.FrameSteps() != 0 {
+		t.Fatalf("want: 2 frames rewound to V0=03, got: %d frames, V0=%02X", got, m.V(0))
+	}
+
+	// and so does the part of a frame left in the history
+	if got := m.Rewind(5); got != 1 || m.V(0) != 3 || m.FrameSteps() != 1 {
+		t.Fatalf("want: 1 frame rewound to the JP at V0=03, got: %d frames, V0=%02X", got, m.V(0))
+	}
+	if got := m.Rewind(1); got != 0 {
+		t.Fatalf("want: nothing rewound, got: %d frames", got)
+	}
+
+	m.LoadROM([]byte{0x12, 0x00})
+	m.Step()
+	m.Reset()
+	if m.StepBack() {
+		t.Fatal("want: history cleared by Reset, got: a step back")
+	}
+}
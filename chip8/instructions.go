@@ -0,0 +1,653 @@
+package chip8
+
+// 0nnn - SYS addr
+// Jump to a machine code routine at nnn.
+//
+// This instruction is only used on the old computers on which Chip-8
+// was originally implemented. It is ignored by modern interpreters.
+func (m *Machine) sysAddr(addr uint16) {
+	m.pc = addr
+}
+
+// 00E0 - CLS
+// Clear the display.
+//
+// Only the selected planes are cleared (XO-CHIP).
+func (m *Machine) cls() {
+	for lin := range m.screen {
+		for col := range m.screen[lin] {
+			m.screen[lin][col] &^= m.plane
+		}
+	}
+	m.display.Draw(m.Screen())
+}
+
+// 00EE - RET
+// Return from a subroutine.
+//
+// The interpreter subtracts 1 from the stack pointer, then sets the program counter to the address at the top of the stack.
+func (m *Machine) ret() error {
+	if m.sp == 0 {
+		return ErrStackUnderflow
+	}
+	m.sp--
+	m.pc = m.stack[m.sp]
+	return nil
+}
+
+// 00Cn - SCD nibble
+// Scroll display n lines down (SUPER-CHIP).
+func (m *Machine) scdN(n uint8) {
+	m.scroll(0, int(n))
+}
+
+// 00Dn - SCU nibble
+// Scroll display n lines up (XO-CHIP).
+func (m *Machine) scuN(n uint8) {
+	m.scroll(0, -int(n))
+}
+
+// 00FB - SCR
+// Scroll display 4 pixels right (SUPER-CHIP).
+func (m *Machine) scr() {
+	m.scroll(4, 0)
+}
+
+// 00FC - SCL
+// Scroll display 4 pixels left (SUPER-CHIP).
+func (m *Machine) scl() {
+	m.scroll(-4, 0)
+}
+
+// scroll moves the selected planes dx pixels right and dy pixels down. The
+// pixels scrolled in are blank.
+func (m *Machine) scroll(dx, dy int) {
+	w, h := m.resolution()
+	var moved [64][128]uint8
+	for lin := 0; lin < h; lin++ {
+		for col := 0; col < w; col++ {
+			from, to := lin-dy, col-dx
+			if from >= 0 && from < h && to >= 0 && to < w {
+				moved[lin][col] = m.screen[from][to] & m.plane
+			}
+		}
+	}
+	for lin := 0; lin < h; lin++ {
+		for col := 0; col < w; col++ {
+			m.screen[lin][col] = m.screen[lin][col]&^m.plane | moved[lin][col]
+		}
+	}
+	m.display.Draw(m.Screen())
+}
+
+// 00FD - EXIT
+// Exit the interpreter (SUPER-CHIP).
+//
+// The machine stops executing instructions until it is reset.
+func (m *Machine) exit() {
+	m.pc -= 2
+	m.exited = true
+}
+
+// 00FE - LOW
+// Disable extended screen mode (SUPER-CHIP).
+//
+// The display goes back to 64x32 pixels and is cleared.
+func (m *Machine) low() {
+	m.hires = false
+	m.cls()
+}
+
+// 00FF - HIGH
+// Enable extended screen mode for full-screen graphics (SUPER-CHIP).
+//
+// The display becomes 128x64 pixels and is cleared.
+func (m *Machine) high() {
+	m.hires = true
+	m.cls()
+}
+
+// 1nnn - JP addr
+// Jump to location nnn.
+//
+// The interpreter sets the program counter to nnn.
+func (m *Machine) jpAddr(addr uint16) {
+	m.pc = addr
+}
+
+// 2nnn - CALL addr
+// Call subroutine at nnn.
+//
+// The interpreter puts the current PC on the top of the stack, then
+// increments the stack pointer. The PC is then set to nnn.
+func (m *Machine) callAddr(addr uint16) error {
+	if int(m.sp) == len(m.stack) {
+		return ErrStackOverflow
+	}
+	m.stack[m.sp] = m.pc
+	m.sp++
+	m.pc = addr
+	return nil
+}
+
+// 3xkk - SE Vx, byte
+// Skip next instruction if Vx = kk.
+//
+// The interpreter compares register Vx to kk, and if they are equal, increments the program counter by 2.
+func (m *Machine) seVxB(x, b uint8) {
+	if m.v[x] == b {
+		m.skip()
+	}
+}
+
+// 4xkk - SNE Vx, byte
+// Skip next instruction if Vx != kk.
+//
+// The interpreter compares register Vx to kk, and if they are not equal, increments the program counter by 2.
+func (m *Machine) sneVxB(x, b uint8) {
+	if m.v[x] != b {
+		m.skip()
+	}
+}
+
+// 5xy0 - SE Vx, Vy
+// Skip next instruction if Vx = Vy.
+//
+// The interpreter compares register Vx to register Vy, and if they are equal, increments the program counter by 2.
+func (m *Machine) seVxVy(x, y uint8) {
+	if m.v[x] == m.v[y] {
+		m.skip()
+	}
+}
+
+// 5xy2 - SAVE Vx, Vy
+// Store registers Vx through Vy in memory starting at location I (XO-CHIP).
+//
+// The registers are stored in reverse order if x > y. I is not changed.
+func (m *Machine) saveVxVy(x, y uint8) error {
+	n, step := regRange(x, y)
+	if err := m.access(m.i, n, true); err != nil {
+		return err
+	}
+	for i, r := 0, int(x); i < n; i, r = i+1, r+step {
+		m.ram[m.i+uint16(i)] = m.v[r]
+	}
+	return nil
+}
+
+// 5xy3 - LOAD Vx, Vy
+// Read registers Vx through Vy from memory starting at location I (XO-CHIP).
+//
+// The registers are read in reverse order if x > y. I is not changed.
+func (m *Machine) loadVxVy(x, y uint8) error {
+	n, step := regRange(x, y)
+	if err := m.access(m.i, n, false); err != nil {
+		return err
+	}
+	for i, r := 0, int(x); i < n; i, r = i+1, r+step {
+		m.v[r] = m.ram[m.i+uint16(i)]
+	}
+	return nil
+}
+
+// regRange returns how many registers there are from x to y, inclusive, and
+// the step to go from one to the next.
+func regRange(x, y uint8) (n, step int) {
+	if x > y {
+		return int(x-y) + 1, -1
+	}
+	return int(y-x) + 1, 1
+}
+
+// 6xkk - LD Vx, byte
+// Set Vx = kk.
+//
+// The interpreter puts the value kk into register Vx.
+func (m *Machine) ldVxB(x, b uint8) {
+	m.v[x] = b
+}
+
+//
+//
+
+// 7xkk - ADD Vx, byte
+// Set Vx = Vx + kk.
+//
+// Adds the value kk to the value of register Vx, then stores the result in Vx.
+func (m *Machine) addVxB(x, b uint8) {
+	m.v[x] += b
+}
+
+// 8xy0 - LD Vx, Vy
+// Set Vx = Vy.
+//
+// Stores the value of register Vy in register Vx.
+func (m *Machine) ldVxVy(x, y uint8) {
+	m.v[x] = m.v[y]
+}
+
+// 8xy1 - OR Vx, Vy
+// Set Vx = Vx OR Vy.
+//
+// Performs a bitwise OR on the values of Vx and Vy, then stores the result in Vx.
+// A bitwise OR compares the corrseponding bits from two values, and if either bit
+// is 1, then the same bit in the result is also 1. Otherwise, it is 0.
+func (m *Machine) orVxVy(x, y uint8) {
+	m.v[x] |= m.v[y]
+	if m.quirks.ResetVF {
+		m.v[0xF] = 0
+	}
+}
+
+// 8xy2 - AND Vx, Vy
+// Set Vx = Vx AND Vy.
+//
+// Performs a bitwise AND on the values of Vx andVxVy Vy, then stores the result in Vx. A bitwise AND compares the corrseponding bits from two values, andVxVy if both bits are 1, then the same bit in the result is also 1. Otherwise, it is 0.
+func (m *Machine) andVxVy(x, y uint8) {
+	m.v[x] &= m.v[y]
+	if m.quirks.ResetVF {
+		m.v[0xF] = 0
+	}
+}
+
+// 8xy3 - XOR Vx, Vy
+// Set Vx = Vx XOR Vy.
+//
+// Performs a bitwise exclusive OR on the values of Vx and Vy, then stores the result in Vx. An exclusive OR compares the corrseponding bits from two values, and if the bits are not both the same, then the corresponding bit in the result is set to 1. Otherwise, it is 0.
+func (m *Machine) xorVxVy(x, y uint8) {
+	m.v[x] ^= m.v[y]
+	if m.quirks.ResetVF {
+		m.v[0xF] = 0
+	}
+}
+
+// 8xy4 - ADD Vx, Vy
+// Set Vx = Vx + Vy, set VF = carry.
+//
+// The values of Vx and Vy are added together. If the result is greater than 8 bits (i.e., > 255,) VF is set to 1, otherwise 0. Only the lowest 8 bits of the result are kept, and stored in Vx.
+func (m *Machine) addVxVy(x, y uint8) {
+	vx := m.v[x] + m.v[y]
+	m.v[0xF] = 0
+	if vx < m.v[x] {
+		m.v[0xF] = 1
+	}
+	m.v[x] = vx
+}
+
+// 8xy5 - SUB Vx, Vy
+// Set Vx = Vx - Vy, set VF = NOT borrow.
+//
+// If Vx > Vy, then VF is set to 1, otherwise 0. Then Vy is subtracted from Vx, and the results stored in Vx.
+func (m *Machine) subVxVy(x, y uint8) {
+	m.v[0xF] = 0
+	if m.v[x] > m.v[y] {
+		m.v[0xF] = 1
+	}
+	m.v[x] -= m.v[y]
+}
+
+// 8xy6 - SHR Vx {, Vy}
+// Set Vx = Vx SHR 1.
+//
+// If the least-significant bit of Vx is 1, then VF is set to 1, otherwise 0. Then Vx is divided by 2.
+// With the ShiftVy quirk, Vy is shifted instead and the result stored in Vx.
+func (m *Machine) shrVx(x, y uint8) {
+	if m.quirks.ShiftVy {
+		m.v[x] = m.v[y]
+	}
+	vf := m.v[x] & 1
+	m.v[x] = m.v[x] >> 1
+	m.v[0xF] = vf
+}
+
+// 8xy7 - SUBN Vx, Vy
+// Set Vx = Vy - Vx, set VF = NOT borrow.
+//
+// If Vy > Vx, then VF is set to 1, otherwise 0. Then Vx is subtracted from Vy, and the results stored in Vx.
+func (m *Machine) subnVxVy(x, y uint8) {
+	m.v[0xF] = 0
+	if m.v[y] > m.v[x] {
+		m.v[0xF] = 1
+	}
+	m.v[x] = m.v[y] - m.v[x]
+}
+
+// 8xyE - SHL Vx {, Vy}
+// Set Vx = Vx SHL 1.
+//
+// If the most-significant bit of Vx is 1, then VF is set to 1, otherwise to 0. Then Vx is multiplied by 2.
+// With the ShiftVy quirk, Vy is shifted instead and the result stored in Vx.
+func (m *Machine) shlVx(x, y uint8) {
+	if m.quirks.ShiftVy {
+		m.v[x] = m.v[y]
+	}
+	vf := m.v[x] >> 7
+	m.v[x] = m.v[x] << 1
+	m.v[0xF] = vf
+}
+
+// 9xy0 - SNE Vx, Vy
+// Skip next instruction if Vx != Vy.
+//
+// The values of Vx and Vy are compared, and if they are not equal, the program counter is increased by 2.
+func (m *Machine) sneVxVy(x, y uint8) {
+	if m.v[x] != m.v[y] {
+		m.skip()
+	}
+}
+
+// Annn - LD I, addr
+// Set I = nnn.
+//
+// The value of register I is set to nnn.
+func (m *Machine) ldIAddr(addr uint16) {
+	m.i = addr
+}
+
+// Bnnn - JP V0, addr
+// Jump to location nnn + V0.
+//
+// The program counter is set to nnn plus the value of V0.
+// With the JumpVx quirk, Vx is used instead, x being the highest nibble of nnn.
+func (m *Machine) jpV0Addr(addr uint16) {
+	if m.quirks.JumpVx {
+		m.pc = addr + uint16(m.v[addr>>8&0xF])
+		return
+	}
+	m.pc = addr + uint16(m.v[0])
+}
+
+// Cxkk - RND Vx, byte
+// Set Vx = random byte AND kk.
+//
+// The interpreter generates a random number from 0 to 255, which is then ANDed with the value kk. The results are stored in Vx. See instruction 8xy2 for more information on AND.
+func (m *Machine) rndVxB(x, b uint8) {
+	m.v[x] = m.rng.Uint8() & b
+}
+
+// Dxyn - DRW Vx, Vy, nibble
+// Display n-byte sprite starting at memory location I at (Vx, Vy), set VF = collision.
+//
+// The interpreter reads n bytes from memory, starting at the address stored in I.
+// These bytes are then displayed as sprites on screen at coordinates (Vx, Vy).
+// Sprites are XORed onto the existing screen.
+// If this causes any pixels to be erased, VF is set to 1, otherwise it is set to 0.
+// If the sprite is positioned so part of it is outside the coordinates of the display,
+// it wraps around to the opposite side of the screen. With the Clip quirk only the
+// starting coordinates wrap, and the parts of the sprite outside the display are cut.
+// See instruction 8xy3 for more information on XOR, and section 2.4, Display, for more
+// information on the Chip-8 screen and sprites.
+//
+// Dxy0 displays a 16x16 sprite made of 32 bytes, two per line (SUPER-CHIP).
+// With both planes selected, the sprite for the second plane follows the one
+// for the first (XO-CHIP).
+func (m *Machine) drwVxVyN(x, y, n uint8) error {
+	w, h := m.resolution()
+	rows, cols := int(n), 8
+	if n == 0 {
+		rows, cols = 16, 16
+	}
+	planes := int(m.plane&1 + m.plane>>1&1)
+	if err := m.access(m.i, planes*rows*cols/8, false); err != nil {
+		return err
+	}
+
+	m.v[0xF] = 0
+	x0, y0 := int(m.v[x])%w, int(m.v[y])%h
+	addr := m.i
+	for _, plane := range []uint8{1, 2} {
+		if m.plane&plane == 0 {
+			continue
+		}
+		for i := 0; i < rows; i++ {
+			var b uint16
+			if cols == 16 {
+				b = uint16(m.ram[addr])<<8 | uint16(m.ram[addr+1])
+				addr += 2
+			} else {
+				b = uint16(m.ram[addr]) << 8
+				addr++
+			}
+
+			lin := y0 + i
+			if lin >= h {
+				if m.quirks.Clip {
+					continue
+				}
+				lin %= h
+			}
+			for j := 0; j < cols; j++ {
+				col := x0 + j
+				if col >= w {
+					if m.quirks.Clip {
+						break
+					}
+					col %= w
+				}
+				if b>>(15-j)&1 == 0 {
+					continue
+				}
+				if m.screen[lin][col]&plane != 0 {
+					m.v[0xF] = 1
+				}
+				m.screen[lin][col] ^= plane
+			}
+		}
+	}
+	m.drew = true
+	m.display.Draw(m.Screen())
+	return nil
+}
+
+// Ex9E - SKP Vx
+// Skip next instruction if key with the value of Vx is pressed.
+//
+// Checks the keyboard, and if the key corresponding to the value of Vx is currently in the down position, PC is increased by 2.
+func (m *Machine) skpVx(x uint8) {
+	if m.keypad.IsKeyDown(m.v[x]) {
+		m.skip()
+	}
+}
+
+// ExA1 - SKNP Vx
+// Skip next instruction if key with the value of Vx is not pressed.
+//
+// Checks the keyboard, and if the key corresponding to the value of Vx is currently in the up position, PC is increased by 2.
+func (m *Machine) sknpVx(x uint8) {
+	if !m.keypad.IsKeyDown(m.v[x]) {
+		m.skip()
+	}
+}
+
+// F000 nnnn - LD I, LONG addr
+// Set I = nnnn, the 16-bit address following the instruction (XO-CHIP).
+func (m *Machine) ldILongAddr(addr uint16) {
+	m.i = addr
+}
+
+// Fn01 - PLANE nibble
+// Select the drawing planes n (XO-CHIP).
+//
+// Bit 0 selects the first plane and bit 1 the second. CLS, scrolling and DRW
+// only affect the selected planes.
+func (m *Machine) planeN(n uint8) {
+	m.plane = n & 0b11
+}
+
+// F002 - AUDIO
+// Load the 16-byte audio pattern from memory starting at location I (XO-CHIP).
+func (m *Machine) audio() error {
+	if err := m.access(m.i, len(m.pattern), false); err != nil {
+		return err
+	}
+	copy(m.pattern[:], m.ram[m.i:])
+	m.updatePattern()
+	return nil
+}
+
+// Fx07 - LD Vx, DT
+// Set Vx = delay timer value.
+//
+// The value of DT is placed into Vx.
+func (m *Machine) ldVxDT(x uint8) {
+	m.v[x] = m.dt
+}
+
+// Fx0A - LD Vx, K
+// Wait for a key press, store the value of the key in Vx.
+//
+// All execution stops until a key is pressed, then the value of that key is stored in Vx.
+// The wait is done by executing this instruction again until it completes,
+// so timers keep running meanwhile. With waitRelease set it completes only
+// once the key is released.
+func (m *Machine) ldVxK(x uint8) {
+	if m.holdingKey {
+		if m.keypad.IsKeyDown(m.heldKey) {
+			m.pc -= 2
+			return
+		}
+		m.v[x] = m.heldKey
+		m.holdingKey = false
+		return
+	}
+
+	for k := uint8(0); k <= 0xF; k++ {
+		if !m.keypad.IsKeyDown(k) {
+			continue
+		}
+		if !m.waitRelease {
+			m.v[x] = k
+			return
+		}
+		m.heldKey = k
+		m.holdingKey = true
+		break
+	}
+	m.pc -= 2
+}
+
+// Fx15 - LD DT, Vx
+// Set delay timer = Vx.
+//
+// DT is set equal to the value of Vx.
+func (m *Machine) ldDTVx(x uint8) {
+	m.dt = m.v[x]
+}
+
+// Fx18 - LD ST, Vx
+// Set sound timer = Vx.
+//
+// ST is set equal to the value of Vx.
+func (m *Machine) ldSTVx(x uint8) {
+	m.st = m.v[x]
+}
+
+// Fx1E - ADD I, Vx
+// Set I = I + Vx.
+//
+// The values of I and Vx are added, and the results are stored in I.
+func (m *Machine) addIVx(x uint8) {
+	m.i += uint16(m.v[x])
+}
+
+// Fx29 - LD F, Vx
+// Set I = location of sprite for digit Vx.
+//
+// The value of I is set to the location for the hexadecimal sprite
+// corresponding to the value of Vx. See section 2.4, Display, for more
+// information on the Chip-8 hexadecimal font.
+func (m *Machine) ldFVx(x uint8) {
+	m.i = fontAddr + uint16(m.v[x]&0xF)*5
+}
+
+// Fx30 - LD HF, Vx
+// Set I = location of the 10-byte sprite for digit Vx (SUPER-CHIP).
+func (m *Machine) ldHFVx(x uint8) {
+	m.i = bigFontAddr + uint16(m.v[x]&0xF)*10
+}
+
+// Fx33 - LD B, Vx
+// Store BCD representation of Vx in memory locations I, I+1, and I+2.
+//
+// The interpreter takes the decimal value of Vx, and places the hundreds digit in memory at location in I, the tens digit at location I+1, and the ones digit at location I+2.
+func (m *Machine) ldBVx(x uint8) error {
+	if err := m.access(m.i, 3, true); err != nil {
+		return err
+	}
+	m.ram[m.i] = m.v[x] / 100
+	m.ram[m.i+1] = m.v[x] % 100 / 10
+	m.ram[m.i+2] = m.v[x] % 10
+	return nil
+}
+
+// Fx55 - LD [I], Vx
+// Store registers V0 through Vx in memory starting at location I.
+//
+// The interpreter copies the values of registers V0 through Vx into memory, starting at the address in I.
+// With the IncrementI quirk, I is left pointing past the last register, and with
+// IncrementIByX at it.
+func (m *Machine) ldIVx(x uint8) error {
+	if err := m.access(m.i, int(x)+1, true); err != nil {
+		return err
+	}
+	for i := uint8(0); i <= x; i++ {
+		m.ram[m.i+uint16(i)] = m.v[i]
+	}
+	switch {
+	case m.quirks.IncrementI:
+		m.i += uint16(x) + 1
+	case m.quirks.IncrementIByX:
+		m.i += uint16(x)
+	}
+	return nil
+}
+
+// Fx65 - LD Vx, [I]
+// Read registers V0 through Vx from memory starting at location I.
+//
+// The interpreter reads values from memory starting at location I into registers V0 through Vx.
+// With the IncrementI quirk, I is left pointing past the last register, and with
+// IncrementIByX at it.
+func (m *Machine) ldVxI(x uint8) error {
+	if err := m.access(m.i, int(x)+1, false); err != nil {
+		return err
+	}
+	for i := uint8(0); i <= x; i++ {
+		m.v[i] = m.ram[m.i+uint16(i)]
+	}
+	switch {
+	case m.quirks.IncrementI:
+		m.i += uint16(x) + 1
+	case m.quirks.IncrementIByX:
+		m.i += uint16(x)
+	}
+	return nil
+}
+
+// Fx3A - PITCH Vx
+// Set the playback rate of the audio pattern to 4000*2^((Vx-64)/48) bits per second (XO-CHIP).
+func (m *Machine) pitchVx(x uint8) {
+	m.pitch = m.v[x]
+	m.updatePattern()
+}
+
+func (m *Machine) updatePattern() {
+	if pb, ok := m.beeper.(PatternBeeper); ok {
+		pb.Pattern(m.pattern, m.pitch)
+	}
+}
+
+// Fx75 - LD R, Vx
+// Store registers V0 through Vx in the RPL user flags (SUPER-CHIP).
+//
+// The HP48 kept these flags across programs, so they survive Reset. The
+// HP48 only had 8 flags, XO-CHIP has 16.
+func (m *Machine) ldRVx(x uint8) {
+	copy(m.rpl[:], m.v[:x+1])
+}
+
+// Fx85 - LD Vx, R
+// Read registers V0 through Vx from the RPL user flags (SUPER-CHIP).
+func (m *Machine) ldVxR(x uint8) {
+	copy(m.v[:x+1], m.rpl[:])
+}
@@ -0,0 +1,391 @@
+package chip8
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func Test_drwVxVyN(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0x500
+		m.ram[0x500] = 0b10111001
+		m.v[0] = 0
+		m.v[1] = 0
+
+		m.drwVxVyN(0, 1, 1)
+
+		got := m.screen[0][:8]
+		want := []uint8{1, 0, 1, 1, 1, 0, 0, 1}
+		if !slices.Equal(got, want) {
+			t.Fatalf("\nwant: %v\ngot: %v\n", want, got)
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0x500
+		m.ram[0x500] = 0b11111111
+		m.ram[0x501] = 0b10000001
+		m.v[0] = 0
+		m.v[1] = 0
+
+		m.drwVxVyN(0, 1, 2)
+
+		got := [][]uint8{
+			m.screen[0][:8],
+			m.screen[1][:8],
+		}
+		want := [][]uint8{
+			{1, 1, 1, 1, 1, 1, 1, 1},
+			{1, 0, 0, 0, 0, 0, 0, 1},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("\nwant: %v\ngot: %v\n", want, got)
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0x500
+		m.ram[0x500] = 0b10001101
+		m.ram[0x501] = 0b10000001
+		m.v[0] = 8
+		m.v[1] = 0
+
+		m.drwVxVyN(0, 1, 2)
+
+		got := m.screen[0][8:16]
+		want := []uint8{1, 0, 0, 0, 1, 1, 0, 1}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("\nwant: %v\ngot: %v\n", want, got)
+		}
+	})
+}
+
+func Test_ldBVx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ge 100", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0
+		m.v[5] = 123
+		m.ldBVx(5)
+
+		want := []uint8{1, 2, 3}
+		got := m.ram[m.i : m.i+3]
+
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("want: %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("ge 10", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0
+		m.v[5] = 54
+		m.ldBVx(5)
+
+		want := []uint8{0, 5, 4}
+		got := m.ram[m.i : m.i+3]
+
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("want: %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("lt 10", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0
+		m.v[5] = 7
+		m.ldBVx(5)
+
+		want := []uint8{0, 0, 7}
+		got := m.ram[m.i : m.i+3]
+
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("want: %v, got: %v", want, got)
+		}
+	})
+}
+
+func Test_ldVxK(t *testing.T) {
+	t.Run("press", func(t *testing.T) {
+		d := &testDevices{}
+		m := New(Config{Keypad: d})
+
+		m.pc = 0x202
+		m.ldVxK(4)
+		if m.pc != 0x200 {
+			t.Fatalf("want: instruction repeated while no key is down, got: PC=%04X", m.pc)
+		}
+
+		d.keys[0xA] = true
+		m.pc = 0x202
+		m.ldVxK(4)
+		if m.pc != 0x202 || m.v[4] != 0xA {
+			t.Fatalf("want: PC=0202 V4=A, got: PC=%04X V4=%X", m.pc, m.v[4])
+		}
+	})
+
+	t.Run("release", func(t *testing.T) {
+		d := &testDevices{}
+		m := New(Config{Keypad: d, WaitRelease: true})
+
+		d.keys[0x7] = true
+		m.pc = 0x202
+		m.ldVxK(4)
+		if m.pc != 0x200 {
+			t.Fatalf("want: instruction repeated while key is held, got: PC=%04X", m.pc)
+		}
+
+		d.keys[0x7] = false
+		m.pc = 0x202
+		m.ldVxK(4)
+		if m.pc != 0x202 || m.v[4] != 0x7 {
+			t.Fatalf("want: PC=0202 V4=7, got: PC=%04X V4=%X", m.pc, m.v[4])
+		}
+	})
+}
+
+func Test_quirks(t *testing.T) {
+	t.Run("shift", func(t *testing.T) {
+		m := New(Config{})
+		m.v[1], m.v[2] = 0b0000_0011, 0b1000_0000
+		m.shrVx(1, 2)
+		if m.v[1] != 0b0000_0001 || m.v[0xF] != 1 {
+			t.Fatalf("want: V1=01 VF=1, got: V1=%02X VF=%d", m.v[1], m.v[0xF])
+		}
+
+		m = New(Config{Quirks: Quirks{ShiftVy: true}})
+		m.v[1], m.v[2] = 0b0000_0011, 0b1000_0000
+		m.shlVx(1, 2)
+		if m.v[1] != 0 || m.v[0xF] != 1 {
+			t.Fatalf("want: V1=00 VF=1, got: V1=%02X VF=%d", m.v[1], m.v[0xF])
+		}
+	})
+
+	t.Run("load store", func(t *testing.T) {
+		tests := []struct {
+			quirks Quirks
+			want   uint16
+		}{
+			{Quirks{}, 0x300},
+			{Quirks{IncrementI: true}, 0x303},
+			{Quirks{IncrementIByX: true}, 0x302},
+		}
+		for _, tt := range tests {
+			m := New(Config{Quirks: tt.quirks})
+			m.i = 0x300
+			m.v = [16]uint8{1, 2, 3}
+			m.ldIVx(2)
+			if got := m.ram[0x300:0x303]; !slices.Equal(got, []uint8{1, 2, 3}) {
+				t.Fatalf("want: [1 2 3], got: %v", got)
+			}
+			if m.i != tt.want {
+				t.Fatalf("%+v: want: I=%03X, got: I=%03X", tt.quirks, tt.want, m.i)
+			}
+		}
+	})
+
+	t.Run("jump", func(t *testing.T) {
+		m := New(Config{Quirks: Quirks{JumpVx: true}})
+		m.v[0], m.v[3] = 1, 2
+		m.jpV0Addr(0x345)
+		if m.pc != 0x347 {
+			t.Fatalf("want: PC=0347, got: PC=%04X", m.pc)
+		}
+	})
+
+	t.Run("reset vf", func(t *testing.T) {
+		m := New(Config{Quirks: Quirks{ResetVF: true}})
+		m.v[0xF] = 1
+		m.orVxVy(0, 1)
+		if m.v[0xF] != 0 {
+			t.Fatalf("want: VF=0, got: VF=%d", m.v[0xF])
+		}
+	})
+
+	t.Run("clip", func(t *testing.T) {
+		for _, clip := range []bool{false, true} {
+			m := New(Config{Quirks: Quirks{Clip: clip}})
+			m.i = 0x300
+			m.ram[0x300] = 0xFF
+			m.v[0], m.v[1] = 60, 0
+			m.drwVxVyN(0, 1, 1)
+			if (m.screen[0][0] == 1) == clip {
+				t.Fatalf("Clip=%v: want: wrapped pixel %v, got: %v", clip, !clip, m.screen[0][0])
+			}
+		}
+	})
+
+	t.Run("presets", func(t *testing.T) {
+		for _, name := range []string{"vip", "chip48", "schip", "modern"} {
+			if _, err := PresetQuirks(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := PresetQuirks("eti660"); err == nil {
+			t.Fatal("want: error for unknown preset, got: nil")
+		}
+	})
+}
+
+func Test_superChip(t *testing.T) {
+	t.Run("resolution", func(t *testing.T) {
+		m := New(Config{})
+		m.screen[0][0] = 1
+		m.high()
+		if f := m.Screen(); f.Width != 128 || f.Height != 64 || f.Pixels[0][0] != 0 {
+			t.Fatalf("want: cleared 128x64 screen, got: %dx%d %v", f.Width, f.Height, f.Pixels[0][0])
+		}
+		m.low()
+		if f := m.Screen(); f.Width != 64 || f.Height != 32 {
+			t.Fatalf("want: 64x32 screen, got: %dx%d", f.Width, f.Height)
+		}
+	})
+
+	t.Run("16x16 sprite", func(t *testing.T) {
+		m := New(Config{})
+		m.high()
+		m.i = 0x300
+		m.ram[0x300] = 0x80
+		m.ram[0x301] = 0x01
+		m.ram[0x31F] = 0x01
+		m.v[0], m.v[1] = 100, 40
+		m.drwVxVyN(0, 1, 0)
+		if m.screen[40][100] == 0 || m.screen[40][115] == 0 || m.screen[55][115] == 0 || m.screen[40][101] != 0 {
+			t.Fatal("want: 16x16 sprite corners drawn")
+		}
+	})
+
+	t.Run("scroll", func(t *testing.T) {
+		m := New(Config{})
+		m.screen[0][10] = 1
+		m.scdN(3)
+		if m.screen[3][10] == 0 || m.screen[0][10] != 0 {
+			t.Fatal("want: pixel scrolled 3 lines down")
+		}
+		m.scr()
+		if m.screen[3][14] == 0 {
+			t.Fatal("want: pixel scrolled 4 pixels right")
+		}
+		m.scl()
+		m.scl()
+		if m.screen[3][6] == 0 || m.screen[3][14] != 0 {
+			t.Fatal("want: pixel scrolled 8 pixels left")
+		}
+	})
+
+	t.Run("big font", func(t *testing.T) {
+		m := New(Config{})
+		m.v[2] = 8
+		m.ldHFVx(2)
+		want := bigFontSet[80:90]
+		if got := m.ram[m.i : m.i+10]; !slices.Equal(got, want) {
+			t.Fatalf("want: %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("rpl flags", func(t *testing.T) {
+		m := New(Config{})
+		m.v = [16]uint8{1, 2, 3, 4}
+		m.ldRVx(3)
+		m.Reset()
+		m.ldVxR(2)
+		if m.v[0] != 1 || m.v[2] != 3 || m.v[3] != 0 {
+			t.Fatalf("want: V0..V2 restored after reset, got: %v", m.v[:4])
+		}
+	})
+
+	t.Run("exit", func(t *testing.T) {
+		m := New(Config{})
+		if err := m.LoadROM([]byte{0x00, 0xFD, 0x60, 0x01}); err != nil {
+			t.Fatal(err)
+		}
+		m.Step()
+		m.Step()
+		if !m.Exited() || m.v[0] != 0 || m.pc != 0x200 {
+			t.Fatalf("want: halted at 0200, got: exited=%v V0=%d PC=%04X", m.Exited(), m.v[0], m.pc)
+		}
+	})
+}
+
+type testPatternBeeper struct {
+	testDevices
+	pattern [16]uint8
+	pitch   uint8
+}
+
+func (b *testPatternBeeper) Pattern(pattern [16]uint8, pitch uint8) {
+	b.pattern, b.pitch = pattern, pitch
+}
+
+func Test_xoChip(t *testing.T) {
+	t.Run("long load and skip", func(t *testing.T) {
+		m := New(Config{})
+		err := m.LoadROM([]byte{
+			0x30, 0x00, // SE V0, 00
+			0xF0, 0x00, 0x12, 0x34, // skipped
+			0xF0, 0x00, 0xAB, 0xCD, // LD I, LONG ABCD
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Step()
+		if m.pc != 0x206 {
+			t.Fatalf("want: 4-byte instruction skipped, got: PC=%04X", m.pc)
+		}
+		m.Step()
+		if m.i != 0xABCD || m.pc != 0x20A {
+			t.Fatalf("want: I=ABCD PC=020A, got: I=%04X PC=%04X", m.i, m.pc)
+		}
+	})
+
+	t.Run("planes", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0x300
+		m.ram[0x300] = 0x80 // first plane
+		m.ram[0x301] = 0xC0 // second plane
+		m.planeN(3)
+		m.drwVxVyN(0, 0, 1)
+		if m.screen[0][0] != 3 || m.screen[0][1] != 2 {
+			t.Fatalf("want: colors [3 2], got: %v", m.screen[0][:2])
+		}
+
+		m.planeN(2)
+		m.cls()
+		if m.screen[0][0] != 1 || m.screen[0][1] != 0 {
+			t.Fatalf("want: only second plane cleared, got: %v", m.screen[0][:2])
+		}
+	})
+
+	t.Run("save load range", func(t *testing.T) {
+		m := New(Config{})
+		m.i = 0x300
+		m.v[2], m.v[3], m.v[4] = 0xA, 0xB, 0xC
+		m.saveVxVy(4, 2)
+		if got := m.ram[0x300:0x303]; !slices.Equal(got, []uint8{0xC, 0xB, 0xA}) {
+			t.Fatalf("want: [C B A], got: %X", got)
+		}
+		m.loadVxVy(7, 9)
+		if m.v[7] != 0xC || m.v[9] != 0xA || m.i != 0x300 {
+			t.Fatalf("want: V7=C V9=A I=300, got: V7=%X V9=%X I=%X", m.v[7], m.v[9], m.i)
+		}
+	})
+
+	t.Run("audio", func(t *testing.T) {
+		b := &testPatternBeeper{}
+		m := New(Config{Beeper: b})
+		m.i = 0x300
+		m.ram[0x300] = 0xF0
+		m.audio()
+		m.v[1] = 100
+		m.pitchVx(1)
+		if b.pattern[0] != 0xF0 || b.pitch != 100 {
+			t.Fatalf("want: pattern F0.. pitch 100, got: %X pitch %d", b.pattern, b.pitch)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package chip8
+
+// Keypad is the input device of the machine, with keys 0x0 through 0xF.
+type Keypad interface {
+	// IsKeyDown reports whether key k is currently pressed.
+	IsKeyDown(k uint8) bool
+}
+
+// FrameKeypad is a Keypad whose keys only change between frames, like one
+// replaying recorded input, so that the program sees the same keys no
+// matter when in the frame it reads them.
+type FrameKeypad interface {
+	Keypad
+
+	// EndFrame is called by Tick at the end of every frame.
+	EndFrame()
+}
+
+// Frame is a snapshot of the display.
+type Frame struct {
+	// Width and Height are the active resolution: 64x32, or 128x64 in the
+	// SUPER-CHIP extended screen mode.
+	Width, Height int
+
+	// Pixels is indexed as [row][column]. Only the top left Width x Height
+	// pixels are in use. Each pixel holds a color from 0 to 3, with bit 0
+	// set if it is on in the first plane and bit 1 if it is on in the
+	// second plane (XO-CHIP).
+	Pixels [64][128]uint8
+}
+
+// Display is the output device of the machine.
+type Display interface {
+	// Draw is called with the framebuffer every time it changes.
+	Draw(f Frame)
+}
+
+// Beeper is the sound device of the machine.
+type Beeper interface {
+	// Beep is called on every timer tick with whether the sound timer is
+	// active, so the tone lasts as many ticks as the value put in ST.
+	Beep(on bool)
+}
+
+// PatternBeeper is a Beeper that can play XO-CHIP audio patterns. Until
+// Pattern is called it should play its default tone.
+type PatternBeeper interface {
+	Beeper
+
+	// Pattern is called when the program sets the audio pattern or its
+	// pitch. The 128 bits of pattern, most significant first, are played
+	// in a loop at 4000*2^((pitch-64)/48) bits per second.
+	Pattern(pattern [16]uint8, pitch uint8)
+}
+
+// MemoryWatcher is told about the memory read and written by instructions,
+// which debuggers use to implement watchpoints. Fetching instructions is
+// not reported.
+type MemoryWatcher interface {
+	// Access is called before n bytes starting at addr are read or
+	// written by the instruction being executed.
+	Access(addr uint16, n int, write bool)
+}
+
+// Config holds the devices a machine is built with. Nil devices are
+// replaced by ones that do nothing.
+type Config struct {
+	Display Display
+	Keypad  Keypad
+	Beeper  Beeper
+
+	// Quirks selects the behavior of ambiguous instructions.
+	Quirks Quirks
+
+	// WaitRelease makes Fx0A (LD Vx, K) complete only once the pressed key
+	// is released, like the COSMAC VIP did.
+	WaitRelease bool
+
+	// IPF is the number of instructions executed per frame by Frame, which
+	// sets the CPU clock. Zero means DefaultIPF.
+	IPF int
+
+	// Random is the source of the random numbers of Cxkk (RND). Nil means a
+	// SplitMix. Only the state of a RandomState is kept by save states and
+	// History.
+	Random Random
+
+	// Seed starts the random numbers, so that runs with the same seed and
+	// input are the same. Zero means DefaultSeed.
+	Seed uint64
+}
+
+type nopDevice struct{}
+
+func (nopDevice) IsKeyDown(k uint8) bool { return false }
+func (nopDevice) Draw(f Frame)           {}
+func (nopDevice) Beep(on bool)           {}
@@ -0,0 +1,425 @@
+// Package chip8 implements a CHIP-8 virtual machine.
+package chip8
+
+import (
+	"fmt"
+)
+
+// ProgramStart is the address where ROMs are loaded and execution begins.
+const ProgramStart = 0x200
+
+// TimerHz is the rate at which the delay and sound timers count down, and so
+// the rate at which Frame should be called.
+const TimerHz = 60
+
+// DefaultIPF is the number of instructions executed per frame when none is
+// configured, roughly the speed of the COSMAC VIP.
+const DefaultIPF = 10
+
+// Machine is a CHIP-8 virtual machine.
+type Machine struct {
+	// general purpose registers
+	v [16]uint8
+
+	// I register (?)
+	i uint16
+
+	// delay timer (DT)
+	dt uint8
+
+	// sound timer (ST)
+	st uint8
+
+	// program counter
+	pc uint16
+
+	// stack pointer, the number of addresses in the stack
+	sp uint16
+
+	// return addresses of the subroutines being executed
+	stack [16]uint16
+
+	// emulated ram, 64 KiB as in XO-CHIP
+	ram [65536]uint8
+
+	// state of screen per pixel, one bit per plane. In low resolution only
+	// the top left 64x32 pixels are used
+	screen [64][128]uint8
+
+	// planes affected by drawing, 1 by default (XO-CHIP)
+	plane uint8
+
+	// audio pattern buffer and its playback pitch (XO-CHIP)
+	pattern [16]uint8
+	pitch   uint8
+
+	// whether the 128x64 extended screen mode is enabled (SUPER-CHIP)
+	hires bool
+
+	// RPL user flags (SUPER-CHIP)
+	rpl [16]uint8
+
+	// whether 00FD (EXIT) was executed
+	exited bool
+
+	// key pressed during Fx0A while waiting for its release
+	heldKey    uint8
+	holdingKey bool
+
+	// whether Dxyn was executed, for the DisplayWait quirk
+	drew bool
+
+	// instructions executed since the timers last ticked
+	steps int
+
+	// random number generator, started from seed on Reset
+	rng  Random
+	seed uint64
+
+	// rom loaded by LoadROM, kept so Reset can reload it
+	rom []byte
+
+	display Display
+	keypad  Keypad
+	beeper  Beeper
+	watcher MemoryWatcher
+	history *History
+
+	quirks      Quirks
+	waitRelease bool
+	ipf         int
+}
+
+// New returns a machine with the font loaded and no ROM.
+func New(cfg Config) *Machine {
+	m := &Machine{
+		display: cfg.Display,
+		keypad:  cfg.Keypad,
+		beeper:  cfg.Beeper,
+
+		quirks:      cfg.Quirks,
+		waitRelease: cfg.WaitRelease,
+		ipf:         cfg.IPF,
+		rng:         cfg.Random,
+		seed:        cfg.Seed,
+	}
+	if m.ipf <= 0 {
+		m.ipf = DefaultIPF
+	}
+	if m.rng == nil {
+		m.rng = &SplitMix{}
+	}
+	if m.seed == 0 {
+		m.seed = DefaultSeed
+	}
+	if m.display == nil {
+		m.display = nopDevice{}
+	}
+	if m.keypad == nil {
+		m.keypad = nopDevice{}
+	}
+	if m.beeper == nil {
+		m.beeper = nopDevice{}
+	}
+	m.Reset()
+	return m
+}
+
+// Reset clears the machine state and reloads the last ROM passed to LoadROM.
+func (m *Machine) Reset() {
+	m.v = [16]uint8{}
+	m.i = 0
+	m.dt = 0
+	m.st = 0
+	m.pc = ProgramStart
+	m.sp = 0
+	m.stack = [16]uint16{}
+	m.heldKey = 0
+	m.holdingKey = false
+	m.ram = [65536]uint8{}
+	m.screen = [64][128]uint8{}
+	m.plane = 1
+	m.pattern = [16]uint8{}
+	m.pitch = 64
+	m.hires = false
+	m.exited = false
+	m.drew = false
+	m.steps = 0
+	m.rng.Seed(m.seed)
+	copy(m.ram[fontAddr:], fontSet)
+	copy(m.ram[bigFontAddr:], bigFontSet)
+	copy(m.ram[ProgramStart:], m.rom)
+	m.display.Draw(m.Screen())
+	if m.history != nil {
+		m.history.clear(m)
+	}
+}
+
+// LoadROM resets the machine and loads rom at ProgramStart.
+func (m *Machine) LoadROM(rom []byte) error {
+	if len(rom) > len(m.ram)-ProgramStart {
+		return fmt.Errorf("rom too large: %d bytes, max is %d", len(rom), len(m.ram)-ProgramStart)
+	}
+	m.rom = append([]byte(nil), rom...)
+	m.Reset()
+	return nil
+}
+
+// Frame executes IPF instructions and then ticks the timers once. With the
+// DisplayWait quirk the frame ends early when a sprite is drawn. If an
+// instruction fails the frame stops there, without ticking the timers.
+func (m *Machine) Frame() error {
+	m.drew = false
+	for i := 0; i < m.ipf; i++ {
+		err := m.Step()
+		if err != nil {
+			return err
+		}
+		if m.quirks.DisplayWait && m.drew {
+			break
+		}
+	}
+	m.Tick()
+	return nil
+}
+
+// Tick counts the delay and sound timers down by one. It is called by Frame,
+// and should only be called directly when stepping through instructions.
+func (m *Machine) Tick() {
+	m.steps = 0
+	if m.dt > 0 {
+		m.dt--
+	}
+	m.beeper.Beep(m.st > 0)
+	if m.st > 0 {
+		m.st--
+	}
+	if k, ok := m.keypad.(FrameKeypad); ok {
+		k.EndFrame()
+	}
+}
+
+// Step executes the instruction at PC. It does nothing once the program has
+// exited. The errors returned are of type *Error.
+func (m *Machine) Step() error {
+	if m.exited {
+		return nil
+	}
+
+	pc := m.pc
+	in := Decode(m.ram[pc:])
+	size := in.Size()
+	if in.Opcode == 0xF000 {
+		// F000 nnnn decodes as invalid when it is cut by the end of memory
+		size = 4
+	}
+	if int(pc)+int(size) > len(m.ram) {
+		return &Error{Err: ErrMemoryOutOfBounds, PC: pc, Opcode: in.Opcode}
+	}
+	if in.Op == OpInvalid {
+		return &Error{Err: ErrUnknownOpcode, PC: pc, Opcode: in.Opcode}
+	}
+
+	if m.history != nil {
+		m.history.begin(m)
+	}
+	m.pc += in.Size()
+	err := handlers[in.Op](m, in)
+	if err != nil {
+		m.pc = pc
+		if m.history != nil {
+			m.history.end(m, true)
+		}
+		return &Error{Err: err, PC: pc, Opcode: in.Opcode}
+	}
+	m.steps++
+	if m.history != nil {
+		m.history.end(m, false)
+	}
+	return nil
+}
+
+// handlers execute the instructions, by Op.
+var handlers = [numOps]func(m *Machine, in Instruction) error{
+	OpCLS:     func(m *Machine, in Instruction) error { m.cls(); return nil },
+	OpRET:     func(m *Machine, in Instruction) error { return m.ret() },
+	OpSCD:     func(m *Machine, in Instruction) error { m.scdN(in.N); return nil },
+	OpSCU:     func(m *Machine, in Instruction) error { m.scuN(in.N); return nil },
+	OpSCR:     func(m *Machine, in Instruction) error { m.scr(); return nil },
+	OpSCL:     func(m *Machine, in Instruction) error { m.scl(); return nil },
+	OpEXIT:    func(m *Machine, in Instruction) error { m.exit(); return nil },
+	OpLOW:     func(m *Machine, in Instruction) error { m.low(); return nil },
+	OpHIGH:    func(m *Machine, in Instruction) error { m.high(); return nil },
+	OpSYS:     func(m *Machine, in Instruction) error { m.sysAddr(in.Addr); return nil },
+	OpJP:      func(m *Machine, in Instruction) error { m.jpAddr(in.Addr); return nil },
+	OpCALL:    func(m *Machine, in Instruction) error { return m.callAddr(in.Addr) },
+	OpSEVxB:   func(m *Machine, in Instruction) error { m.seVxB(in.X, in.B); return nil },
+	OpSNEVxB:  func(m *Machine, in Instruction) error { m.sneVxB(in.X, in.B); return nil },
+	OpSEVxVy:  func(m *Machine, in Instruction) error { m.seVxVy(in.X, in.Y); return nil },
+	OpSAVE:    func(m *Machine, in Instruction) error { return m.saveVxVy(in.X, in.Y) },
+	OpLOAD:    func(m *Machine, in Instruction) error { return m.loadVxVy(in.X, in.Y) },
+	OpLDVxB:   func(m *Machine, in Instruction) error { m.ldVxB(in.X, in.B); return nil },
+	OpADDVxB:  func(m *Machine, in Instruction) error { m.addVxB(in.X, in.B); return nil },
+	OpLDVxVy:  func(m *Machine, in Instruction) error { m.ldVxVy(in.X, in.Y); return nil },
+	OpOR:      func(m *Machine, in Instruction) error { m.orVxVy(in.X, in.Y); return nil },
+	OpAND:     func(m *Machine, in Instruction) error { m.andVxVy(in.X, in.Y); return nil },
+	OpXOR:     func(m *Machine, in Instruction) error { m.xorVxVy(in.X, in.Y); return nil },
+	OpADDVxVy: func(m *Machine, in Instruction) error { m.addVxVy(in.X, in.Y); return nil },
+	OpSUB:     func(m *Machine, in Instruction) error { m.subVxVy(in.X, in.Y); return nil },
+	OpSHR:     func(m *Machine, in Instruction) error { m.shrVx(in.X, in.Y); return nil },
+	OpSUBN:    func(m *Machine, in Instruction) error { m.subnVxVy(in.X, in.Y); return nil },
+	OpSHL:     func(m *Machine, in Instruction) error { m.shlVx(in.X, in.Y); return nil },
+	OpSNEVxVy: func(m *Machine, in Instruction) error { m.sneVxVy(in.X, in.Y); return nil },
+	OpLDI:     func(m *Machine, in Instruction) error { m.ldIAddr(in.Addr); return nil },
+	OpJPV0:    func(m *Machine, in Instruction) error { m.jpV0Addr(in.Addr); return nil },
+	OpRND:     func(m *Machine, in Instruction) error { m.rndVxB(in.X, in.B); return nil },
+	OpDRW:     func(m *Machine, in Instruction) error { return m.drwVxVyN(in.X, in.Y, in.N) },
+	OpSKP:     func(m *Machine, in Instruction) error { m.skpVx(in.X); return nil },
+	OpSKNP:    func(m *Machine, in Instruction) error { m.sknpVx(in.X); return nil },
+	OpLDILong: func(m *Machine, in Instruction) error { m.ldILongAddr(in.Addr); return nil },
+	OpPLANE:   func(m *Machine, in Instruction) error { m.planeN(in.X); return nil },
+	OpAUDIO:   func(m *Machine, in Instruction) error { return m.audio() },
+	OpLDVxDT:  func(m *Machine, in Instruction) error { m.ldVxDT(in.X); return nil },
+	OpLDVxK:   func(m *Machine, in Instruction) error { m.ldVxK(in.X); return nil },
+	OpLDDTVx:  func(m *Machine, in Instruction) error { m.ldDTVx(in.X); return nil },
+	OpLDSTVx:  func(m *Machine, in Instruction) error { m.ldSTVx(in.X); return nil },
+	OpADDIVx:  func(m *Machine, in Instruction) error { m.addIVx(in.X); return nil },
+	OpLDFVx:   func(m *Machine, in Instruction) error { m.ldFVx(in.X); return nil },
+	OpLDHFVx:  func(m *Machine, in Instruction) error { m.ldHFVx(in.X); return nil },
+	OpLDBVx:   func(m *Machine, in Instruction) error { return m.ldBVx(in.X) },
+	OpPITCH:   func(m *Machine, in Instruction) error { m.pitchVx(in.X); return nil },
+	OpLDIVx:   func(m *Machine, in Instruction) error { return m.ldIVx(in.X) },
+	OpLDVxI:   func(m *Machine, in Instruction) error { return m.ldVxI(in.X) },
+	OpLDRVx:   func(m *Machine, in Instruction) error { m.ldRVx(in.X); return nil },
+	OpLDVxR:   func(m *Machine, in Instruction) error { m.ldVxR(in.X); return nil },
+}
+
+// access returns ErrMemoryOutOfBounds unless the n bytes starting at addr
+// are all in memory. Otherwise it tells the watcher about the access.
+func (m *Machine) access(addr uint16, n int, write bool) error {
+	if int(addr)+n > len(m.ram) {
+		return ErrMemoryOutOfBounds
+	}
+	if m.watcher != nil {
+		m.watcher.Access(addr, n, write)
+	}
+	if m.history != nil && write {
+		m.history.write(m, addr, n)
+	}
+	return nil
+}
+
+// skip skips the next instruction, which is 4 bytes long if it is F000 nnnn.
+func (m *Machine) skip() {
+	m.pc += m.InstructionAt(m.pc).Size()
+}
+
+// Fetch returns the big-endian opcode stored at addr.
+func (m *Machine) Fetch(addr uint16) uint16 {
+	hi, lo := m.ram[addr], m.ram[addr+1]
+	return uint16(hi)<<8 | uint16(lo)
+}
+
+// InstructionAt decodes the instruction stored at addr.
+func (m *Machine) InstructionAt(addr uint16) Instruction {
+	return Decode(m.ram[addr:])
+}
+
+// IPF returns the number of instructions executed per frame.
+func (m *Machine) IPF() int { return m.ipf }
+
+// FrameSteps returns the number of instructions executed since the timers
+// last ticked.
+func (m *Machine) FrameSteps() int { return m.steps }
+
+// Seed returns the seed of the random numbers.
+func (m *Machine) Seed() uint64 { return m.seed }
+
+// Quirks returns the quirks the machine was built with.
+func (m *Machine) Quirks() Quirks { return m.quirks }
+
+// V returns the value of register Vx.
+func (m *Machine) V(x uint8) uint8 { return m.v[x&0xF] }
+
+// Registers returns V0 through VF.
+func (m *Machine) Registers() [16]uint8 { return m.v }
+
+// I returns the I register.
+func (m *Machine) I() uint16 { return m.i }
+
+// PC returns the program counter.
+func (m *Machine) PC() uint16 { return m.pc }
+
+// SP returns the stack pointer.
+func (m *Machine) SP() uint16 { return m.sp }
+
+// DT returns the delay timer.
+func (m *Machine) DT() uint8 { return m.dt }
+
+// ST returns the sound timer.
+func (m *Machine) ST() uint8 { return m.st }
+
+// Stack returns a copy of the call stack.
+func (m *Machine) Stack() [16]uint16 { return m.stack }
+
+// Peek returns the byte stored at addr.
+func (m *Machine) Peek(addr uint16) uint8 { return m.ram[addr] }
+
+// SetV sets register Vx to b.
+func (m *Machine) SetV(x uint8, b uint8) { m.v[x&0xF] = b }
+
+// SetI sets the I register.
+func (m *Machine) SetI(addr uint16) { m.i = addr }
+
+// SetPC sets the program counter.
+func (m *Machine) SetPC(addr uint16) { m.pc = addr }
+
+// SetDT sets the delay timer.
+func (m *Machine) SetDT(b uint8) { m.dt = b }
+
+// SetST sets the sound timer.
+func (m *Machine) SetST(b uint8) { m.st = b }
+
+// Poke stores b at addr.
+func (m *Machine) Poke(addr uint16, b uint8) { m.ram[addr] = b }
+
+// ROMSize returns the size of the ROM loaded by LoadROM.
+func (m *Machine) ROMSize() int { return len(m.rom) }
+
+// RAM returns a copy of the memory.
+func (m *Machine) RAM() []uint8 { return append([]uint8(nil), m.ram[:]...) }
+
+// Screen returns a copy of the framebuffer.
+func (m *Machine) Screen() Frame {
+	w, h := m.resolution()
+	return Frame{Width: w, Height: h, Pixels: m.screen}
+}
+
+// Exited reports whether the program exited with 00FD.
+func (m *Machine) Exited() bool { return m.exited }
+
+// SetMemoryWatcher makes w be told about the memory accessed by
+// instructions. A nil w removes the watcher.
+func (m *Machine) SetMemoryWatcher(w MemoryWatcher) { m.watcher = w }
+
+// rngState returns the state of the random number generator, if it can be
+// saved.
+func (m *Machine) rngState() uint64 {
+	if r, ok := m.rng.(RandomState); ok {
+		return r.State()
+	}
+	return 0
+}
+
+func (m *Machine) setRNGState(state uint64) {
+	if r, ok := m.rng.(RandomState); ok {
+		r.SetState(state)
+	}
+}
+
+func (m *Machine) resolution() (w, h int) {
+	if m.hires {
+		return 128, 64
+	}
+	return 64, 32
+}
@@ -0,0 +1,203 @@
+package chip8
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func Test_chip8_cls(t *testing.T) {
+	m := New(Config{})
+	empty := m.screen
+
+	// fill screen with random data
+	for x := range m.screen {
+		for y := range m.screen[x] {
+			m.screen[x][y] = uint8(rand.Intn(2))
+		}
+	}
+
+	m.cls()
+	if m.screen != empty {
+		t.Fatalf("want: empty screen, got: %#v", m.screen)
+	}
+}
+
+func TestMachine_LoadROM(t *testing.T) {
+	m := New(Config{})
+	rom := []byte{0x60, 0x2A, 0x12, 0x02}
+	if err := m.LoadROM(rom); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Step()
+	if m.V(0) != 0x2A || m.PC() != 0x202 {
+		t.Fatalf("want: V0=2A PC=0202, got: V0=%02X PC=%04X", m.V(0), m.PC())
+	}
+
+	m.Reset()
+	if m.V(0) != 0 || m.PC() != ProgramStart || m.Fetch(ProgramStart) != 0x602A {
+		t.Fatalf("reset did not reload rom: V0=%02X PC=%04X", m.V(0), m.PC())
+	}
+
+	if err := m.LoadROM(make([]byte, 0x10000)); err == nil {
+		t.Fatal("want: error for oversized rom, got: nil")
+	}
+}
+
+type testDevices struct {
+	keys  [16]bool
+	draws int
+	beeps []bool
+	last  Frame
+}
+
+func (d *testDevices) IsKeyDown(k uint8) bool { return d.keys[k] }
+func (d *testDevices) Draw(f Frame)           { d.draws++; d.last = f }
+func (d *testDevices) Beep(on bool)           { d.beeps = append(d.beeps, on) }
+
+func TestMachine_devices(t *testing.T) {
+	d := &testDevices{}
+	d.keys[3] = true
+	m := New(Config{Display: d, Keypad: d, Beeper: d})
+	err := m.LoadROM([]byte{
+		0x61, 0x03, // LD V1, 03
+		0xE1, 0x9E, // SKP V1
+		0x00, 0x00, // skipped
+		0xF2, 0x0A, // LD V2, K
+		0xF2, 0x29, // LD F, V2
+		0xD0, 0x05, // DRW V0, V0, 5
+		0xF1, 0x18, // LD ST, V1
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		m.Step()
+	}
+
+	if m.V(2) != 0x3 {
+		t.Fatalf("want: V2=3, got: V2=%X", m.V(2))
+	}
+	if d.last.Pixels[1][3] != 1 || d.last.Pixels[1][0] != 0 {
+		t.Fatalf("want: glyph 3 drawn, got: %v", d.last.Pixels[1][:8])
+	}
+	if len(d.beeps) != 0 {
+		t.Fatalf("want: no beeps without ticks, got: %v", d.beeps)
+	}
+}
+
+func TestMachine_Frame(t *testing.T) {
+	d := &testDevices{}
+	m := New(Config{Beeper: d, IPF: 4})
+	err := m.LoadROM([]byte{
+		0x60, 0x02, // LD V0, 02
+		0xF0, 0x15, // LD DT, V0
+		0xF0, 0x18, // LD ST, V0
+		0x12, 0x06, // JP 0206
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Frame()
+	if m.DT() != 1 || m.ST() != 1 {
+		t.Fatalf("want: DT=1 ST=1 after one frame, got: DT=%d ST=%d", m.DT(), m.ST())
+	}
+	if m.PC() != 0x206 {
+		t.Fatalf("want: instructions executed while DT > 0, got: PC=%04X", m.PC())
+	}
+
+	m.Frame()
+	m.Frame()
+	if m.DT() != 0 || m.ST() != 0 {
+		t.Fatalf("want: timers stopped at 0, got: DT=%d ST=%d", m.DT(), m.ST())
+	}
+	want := []bool{true, true, false}
+	if !slices.Equal(d.beeps, want) {
+		t.Fatalf("want: beeps %v, got: %v", want, d.beeps)
+	}
+}
+
+func TestMachine_Step_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		rom  []byte
+		i    uint16
+		want error
+		pc   uint16
+	}{
+		{"unknown opcode", []byte{0xE0, 0x00}, 0, ErrUnknownOpcode, 0x200},
+		{"stack underflow", []byte{0x00, 0xEE}, 0, ErrStackUnderflow, 0x200},
+		{"stack overflow", []byte{0x22, 0x00}, 0, ErrStackOverflow, 0x200},
+		{"draw out of memory", []byte{0xD0, 0x0F}, 0xFFF8, ErrMemoryOutOfBounds, 0x200},
+		{"store out of memory", []byte{0xFF, 0x55}, 0xFFF8, ErrMemoryOutOfBounds, 0x200},
+		{"fetch out of memory", nil, 0, ErrMemoryOutOfBounds, 0xFFFF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(Config{})
+			if err := m.LoadROM(tt.rom); err != nil {
+				t.Fatal(err)
+			}
+			m.i = tt.i
+			if tt.rom == nil {
+				m.pc = tt.pc
+			}
+
+			var err error
+			for n := 0; n < 20 && err == nil; n++ {
+				err = m.Step()
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("want: %v, got: %v", tt.want, err)
+			}
+
+			var e *Error
+			if !errors.As(err, &e) || e.PC != tt.pc || e.Opcode != m.InstructionAt(tt.pc).Opcode {
+				t.Fatalf("want: error at %04X, got: %#v", tt.pc, err)
+			}
+			if m.PC() != tt.pc {
+				t.Fatalf("want: PC left at %04X, got: %04X", tt.pc, m.PC())
+			}
+		})
+	}
+}
+
+func TestMachine_Seed(t *testing.T) {
+	rom := []byte{
+		0xC0, 0xFF, // 200: RND V0, FF
+		0xC1, 0xFF, // 202: RND V1, FF
+		0xC2, 0xFF, // 204: RND V2, FF
+	}
+	run := func(seed uint64) [16]uint8 {
+		m := New(Config{Seed: seed})
+		if err := m.LoadROM(rom); err != nil {
+			t.Fatal(err)
+		}
+		m.Frame()
+		return m.Registers()
+	}
+
+	a, b := run(1), run(1)
+	if a != b {
+		t.Fatalf("want: same numbers for the same seed, got: %X and %X", a[:3], b[:3])
+	}
+	if c := run(2); a == c {
+		t.Fatalf("want: other numbers for another seed, got: %X", c[:3])
+	}
+
+	m := New(Config{Seed: 1})
+	m.LoadROM(rom)
+	m.Frame()
+	m.Reset()
+	m.Frame()
+	if m.Registers() != a {
+		t.Fatal("want: numbers started again by Reset, got: others")
+	}
+	if got := New(Config{}).Seed(); got != DefaultSeed {
+		t.Fatalf("want: seed %d by default, got: %d", DefaultSeed, got)
+	}
+}
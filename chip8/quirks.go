@@ -0,0 +1,77 @@
+package chip8
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Quirks selects between the behaviors different interpreters gave to the
+// same instructions. The zero value is the modern behavior.
+type Quirks struct {
+	// ShiftVy makes 8xy6 and 8xyE shift Vy and store the result in Vx,
+	// instead of shifting Vx in place.
+	ShiftVy bool
+
+	// IncrementI makes Fx55 and Fx65 leave I pointing past the last
+	// register stored or loaded.
+	IncrementI bool
+
+	// IncrementIByX makes Fx55 and Fx65 leave I pointing at the last
+	// register stored or loaded, one short of IncrementI, like CHIP-48 did.
+	// IncrementI takes precedence over it.
+	IncrementIByX bool
+
+	// JumpVx makes Bnnn jump to nnn + Vx, x being the highest nibble of nnn,
+	// instead of nnn + V0.
+	JumpVx bool
+
+	// ResetVF makes 8xy1, 8xy2 and 8xy3 set VF to 0.
+	ResetVF bool
+
+	// Clip makes Dxyn cut sprites at the edges of the screen instead of
+	// wrapping them around to the opposite side.
+	Clip bool
+
+	// DisplayWait makes Dxyn end the frame, like the COSMAC VIP which waited
+	// for the vertical blank before drawing.
+	DisplayWait bool
+}
+
+// Presets are the quirks of well known interpreters, by name.
+var Presets = map[string]Quirks{
+	"vip": {
+		ShiftVy:     true,
+		IncrementI:  true,
+		ResetVF:     true,
+		Clip:        true,
+		DisplayWait: true,
+	},
+	"chip48": {
+		IncrementIByX: true,
+		JumpVx:        true,
+		Clip:          true,
+	},
+	"schip": {
+		JumpVx: true,
+		Clip:   true,
+	},
+	"xochip": {
+		IncrementI: true,
+	},
+	"modern": {},
+}
+
+// PresetQuirks returns the quirks of the preset called name.
+func PresetQuirks(name string) (Quirks, error) {
+	q, ok := Presets[name]
+	if !ok {
+		names := make([]string, 0, len(Presets))
+		for n := range Presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Quirks{}, fmt.Errorf("unknown quirks %q, want one of: %s", name, strings.Join(names, ", "))
+	}
+	return q, nil
+}
@@ -0,0 +1,100 @@
+package chip8
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultSeed is the seed of the random numbers when none is configured, so
+// that runs are the same unless asked otherwise.
+const DefaultSeed = 1
+
+// Random is a source of random numbers for Cxkk (RND).
+type Random interface {
+	// Seed starts the numbers again from seed. It is called by Reset.
+	Seed(seed uint64)
+
+	// Uint8 returns the next random number.
+	Uint8() uint8
+}
+
+// RandomState is a Random whose state can be saved and restored, which
+// save states and History need to give the same numbers again.
+type RandomState interface {
+	Random
+	State() uint64
+	SetState(state uint64)
+}
+
+// Randoms are the random number generators, by name.
+var Randoms = map[string]func() Random{
+	"splitmix": func() Random { return &SplitMix{} },
+	"lfsr":     func() Random { return &LFSR{} },
+}
+
+// NewRandom returns a new random number generator of the kind called name.
+func NewRandom(name string) (Random, error) {
+	newRandom, ok := Randoms[name]
+	if !ok {
+		names := make([]string, 0, len(Randoms))
+		for n := range Randoms {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown random number generator %q, want one of: %s", name, strings.Join(names, ", "))
+	}
+	return newRandom(), nil
+}
+
+// SplitMix is the SplitMix64 generator, fast and of good quality. It is the
+// generator used when none is configured.
+type SplitMix struct {
+	state uint64
+}
+
+func (r *SplitMix) Seed(seed uint64)      { r.state = seed }
+func (r *SplitMix) State() uint64         { return r.state }
+func (r *SplitMix) SetState(state uint64) { r.state = state }
+
+func (r *SplitMix) Uint8() uint8 {
+	r.state += 0x9E3779B97F4A7C15
+	z := r.state
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return uint8(z >> 56)
+}
+
+// LFSR is a 16 bit linear feedback shift register, shifted once per
+// number, in the spirit of the cheap generators of 8 bit machines like the
+// COSMAC VIP. Its numbers repeat every 65535 and each one is mostly the
+// previous one shifted, patterns that programs written for those machines
+// may have been tuned to. It is not an exact model of the VIP interpreter.
+type LFSR struct {
+	state uint16
+}
+
+// lfsrTaps are the taps of x^16 + x^14 + x^13 + x^11 + 1, a polynomial of
+// maximal period.
+const lfsrTaps = 0xB400
+
+func (r *LFSR) Seed(seed uint64) { r.SetState(seed ^ seed>>16 ^ seed>>32 ^ seed>>48) }
+func (r *LFSR) State() uint64    { return uint64(r.state) }
+
+func (r *LFSR) SetState(state uint64) {
+	r.state = uint16(state)
+	if r.state == 0 {
+		// a register of zeros stays zero
+		r.state = 1
+	}
+}
+
+func (r *LFSR) Uint8() uint8 {
+	lsb := r.state & 1
+	r.state >>= 1
+	if lsb != 0 {
+		r.state ^= lfsrTaps
+	}
+	return uint8(r.state)
+}
@@ -0,0 +1,49 @@
+package chip8
+
+import "testing"
+
+func TestLFSR(t *testing.T) {
+	r := &LFSR{}
+	r.Seed(0)
+	start := r.State()
+	if start == 0 {
+		t.Fatal("want: a register that isn't zero, got: 0")
+	}
+
+	period := 0
+	for {
+		r.Uint8()
+		period++
+		if r.State() == start {
+			break
+		}
+	}
+	if period != 0xFFFF {
+		t.Fatalf("want: period FFFF, got: %X", period)
+	}
+}
+
+type constRandom uint8
+
+func (r constRandom) Seed(seed uint64) {}
+func (r constRandom) Uint8() uint8     { return uint8(r) }
+
+func TestMachine_Random(t *testing.T) {
+	m := New(Config{Random: constRandom(0xAB)})
+	if err := m.LoadROM([]byte{0xC0, 0x0F}); err != nil { // RND V0, 0F
+		t.Fatal(err)
+	}
+	m.Step()
+	if m.V(0) != 0x0B {
+		t.Fatalf("want: V0=0B, got: %02X", m.V(0))
+	}
+
+	for name := range Randoms {
+		if _, err := NewRandom(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewRandom("dice"); err == nil {
+		t.Fatal("want: error for an unknown generator, got: nil")
+	}
+}
@@ -0,0 +1,162 @@
+package chip8
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// stateMagic starts every save state, followed by stateVersion.
+const (
+	stateMagic   = "CH8S"
+	stateVersion = 1
+)
+
+// ErrInvalidState is returned by UnmarshalBinary for data that isn't a save
+// state it can read.
+var ErrInvalidState = errors.New("invalid save state")
+
+// MarshalBinary returns a save state with everything needed to resume the
+// machine later: registers, timers, stack, memory, screen, the ROM and the
+// configuration, except for the devices. Of the keypad it only has the key
+// Fx0A is waiting to be released, as the keys held down belong to the
+// Keypad, which is read again when the machine resumes. It implements
+// encoding.BinaryMarshaler.
+func (m *Machine) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(stateMagic)+2+len(m.ram)+len(m.screen)*len(m.screen[0])+len(m.rom)+256)
+	b = append(b, stateMagic...)
+	b = binary.BigEndian.AppendUint16(b, stateVersion)
+
+	b = append(b, m.v[:]...)
+	b = binary.BigEndian.AppendUint16(b, m.i)
+	b = append(b, m.dt, m.st)
+	b = binary.BigEndian.AppendUint16(b, m.pc)
+	b = binary.BigEndian.AppendUint16(b, m.sp)
+	for _, addr := range m.stack {
+		b = binary.BigEndian.AppendUint16(b, addr)
+	}
+	b = append(b, m.heldKey, boolByte(m.holdingKey), boolByte(m.exited))
+	b = binary.BigEndian.AppendUint32(b, uint32(m.steps))
+	b = binary.BigEndian.AppendUint64(b, m.seed)
+	b = binary.BigEndian.AppendUint64(b, m.rngState())
+
+	b = append(b, m.ram[:]...)
+	for _, row := range m.screen {
+		b = append(b, row[:]...)
+	}
+	b = append(b, m.plane, boolByte(m.hires))
+	b = append(b, m.pattern[:]...)
+	b = append(b, m.pitch)
+	b = append(b, m.rpl[:]...)
+
+	q := m.quirks
+	for _, quirk := range []bool{q.ShiftVy, q.IncrementI, q.IncrementIByX, q.JumpVx, q.ResetVF, q.Clip, q.DisplayWait} {
+		b = append(b, boolByte(quirk))
+	}
+	b = append(b, boolByte(m.waitRelease))
+	b = binary.BigEndian.AppendUint32(b, uint32(m.ipf))
+
+	b = binary.BigEndian.AppendUint32(b, uint32(len(m.rom)))
+	b = append(b, m.rom...)
+	return b, nil
+}
+
+// UnmarshalBinary restores a save state made by MarshalBinary, and then
+// sends the restored screen and audio pattern to the devices. The machine
+// is left unchanged if the state is invalid. It implements
+// encoding.BinaryUnmarshaler.
+func (m *Machine) UnmarshalBinary(data []byte) error {
+	r := stateReader{data: data}
+	if string(r.bytes(len(stateMagic))) != stateMagic {
+		return fmt.Errorf("%w: missing %s header", ErrInvalidState, stateMagic)
+	}
+	if v := r.uint16(); r.err == nil && v != stateVersion {
+		return fmt.Errorf("%w: version %d, want %d", ErrInvalidState, v, stateVersion)
+	}
+
+	s := *m
+	copy(s.v[:], r.bytes(len(s.v)))
+	s.i = r.uint16()
+	s.dt, s.st = r.byte(), r.byte()
+	s.pc = r.uint16()
+	s.sp = r.uint16()
+	for i := range s.stack {
+		s.stack[i] = r.uint16()
+	}
+	s.heldKey, s.holdingKey, s.exited = r.byte(), r.bool(), r.bool()
+	s.steps = int(r.uint32())
+	s.seed = r.uint64()
+	rng := r.uint64()
+
+	copy(s.ram[:], r.bytes(len(s.ram)))
+	for i := range s.screen {
+		copy(s.screen[i][:], r.bytes(len(s.screen[i])))
+	}
+	s.plane, s.hires = r.byte(), r.bool()
+	copy(s.pattern[:], r.bytes(len(s.pattern)))
+	s.pitch = r.byte()
+	copy(s.rpl[:], r.bytes(len(s.rpl)))
+
+	q := &s.quirks
+	for _, quirk := range []*bool{&q.ShiftVy, &q.IncrementI, &q.IncrementIByX, &q.JumpVx, &q.ResetVF, &q.Clip, &q.DisplayWait} {
+		*quirk = r.bool()
+	}
+	s.waitRelease = r.bool()
+	s.ipf = int(r.uint32())
+
+	n := r.uint32()
+	if r.err == nil && n > uint32(len(s.ram)-ProgramStart) {
+		return fmt.Errorf("%w: rom too large", ErrInvalidState)
+	}
+	s.rom = append([]byte(nil), r.bytes(int(n))...)
+
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) > 0 {
+		return fmt.Errorf("%w: %d bytes left over", ErrInvalidState, len(r.data))
+	}
+	if s.sp > uint16(len(s.stack)) || s.plane > 3 || s.heldKey > 0xF || s.ipf <= 0 {
+		return fmt.Errorf("%w: registers out of range", ErrInvalidState)
+	}
+
+	*m = s
+	m.setRNGState(rng)
+	m.display.Draw(m.Screen())
+	m.updatePattern()
+	if m.history != nil {
+		m.history.clear(m)
+	}
+	return nil
+}
+
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// stateReader reads the fields of a save state, remembering if it ran out
+// of data.
+type stateReader struct {
+	data []byte
+	err  error
+}
+
+func (r *stateReader) bytes(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.err = fmt.Errorf("%w: truncated", ErrInvalidState)
+		r.data = nil
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *stateReader) byte() uint8    { return r.bytes(1)[0] }
+func (r *stateReader) bool() bool     { return r.byte() != 0 }
+func (r *stateReader) uint16() uint16 { return binary.BigEndian.Uint16(r.bytes(2)) }
+func (r *stateReader) uint32() uint32 { return binary.BigEndian.Uint32(r.bytes(4)) }
+func (r *stateReader) uint64() uint64 { return binary.BigEndian.Uint64(r.bytes(8)) }
@@ -0,0 +1,97 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMachine_MarshalBinary(t *testing.T) {
+	d := &testDevices{}
+	m := New(Config{Quirks: Presets["vip"], IPF: 7})
+	err := m.LoadROM([]byte{
+		0x00, 0xFF, // HIGH
+		0x22, 0x08, // CALL 0208
+		0xD0, 0x15, // DRW V0, V1, 5
+		0x12, 0x06, // JP 0206
+		0x60, 0x05, // LD V0, 05
+		0xF0, 0x15, // LD DT, V0
+		0xA0, 0x50, // LD I, 0050
+		0x00, 0xEE, // RET
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		m.Step()
+	}
+
+	state, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := New(Config{Display: d})
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	if restored.PC() != m.PC() || restored.DT() != 5 || restored.I() != 0x50 || restored.Quirks() != m.Quirks() || restored.IPF() != 7 {
+		t.Fatalf("want: state of the saved machine, got: PC=%04X DT=%02X I=%04X quirks=%+v IPF=%d",
+			restored.PC(), restored.DT(), restored.I(), restored.Quirks(), restored.IPF())
+	}
+	if d.last != m.Screen() {
+		t.Fatal("want: restored screen drawn, got: a different one")
+	}
+
+	m.Frame()
+	restored.Frame()
+	a, _ := m.MarshalBinary()
+	b, _ := restored.MarshalBinary()
+	if !bytes.Equal(a, b) {
+		t.Fatal("want: both machines running the same, got: different states")
+	}
+
+	m.Reset()
+	restored.Reset()
+	if restored.Fetch(ProgramStart) != 0x00FF {
+		t.Fatalf("want: ROM restored for Reset, got: %04X at 0200", restored.Fetch(ProgramStart))
+	}
+}
+
+func TestMachine_UnmarshalBinary_errors(t *testing.T) {
+	m := New(Config{})
+	state, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	badVersion := append([]byte(nil), state...)
+	badVersion[5] = 99
+	badSP := append([]byte(nil), state...)
+	badSP[len(stateMagic)+2+16+2+2+2+1] = 17 // low byte of SP
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"magic", append([]byte("CH8X"), state[4:]...)},
+		{"version", badVersion},
+		{"truncated", state[:len(state)-1]},
+		{"trailing", append(append([]byte(nil), state...), 0)},
+		{"stack pointer", badSP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.SetV(0, 0x2A)
+			err := m.UnmarshalBinary(tt.data)
+			if !errors.Is(err, ErrInvalidState) {
+				t.Fatalf("want: %v, got: %v", ErrInvalidState, err)
+			}
+			if m.V(0) != 0x2A {
+				t.Fatal("want: machine unchanged, got: V0 restored")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/igoracmelo/ch8/asm"
+)
+
+// subcommands are run instead of the emulator when their name is the first
+// argument.
+var subcommands = map[string]func(args []string) error{
+	"asm":    asmCmd,
+	"disasm": disasmCmd,
+	"run":    emulate,
+}
+
+// parseArgs parses the flags in args, which unlike with fs.Parse may come
+// after the positional arguments, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+// writeOutput writes b to the file named out, or to stdout if out is "".
+func writeOutput(out string, b []byte) error {
+	if out == "" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(out, b, 0o644)
+}
+
+func disasmCmd(args []string) error {
+	fs := flag.NewFlagSet("disasm", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ch8 disasm [-o file.8s] rom.ch8")
+		fs.PrintDefaults()
+	}
+	out := fs.String("o", "", "write the source to this file instead of stdout")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rom, err := os.ReadFile(pos[0])
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, []byte(asm.Disassemble(rom)))
+}
+
+func asmCmd(args []string) error {
+	fs := flag.NewFlagSet("asm", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ch8 asm [-o file.ch8] file.8s")
+		fs.PrintDefaults()
+	}
+	out := fs.String("o", "", "write the ROM to this file instead of stdout")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rom, err := asm.Assemble(pos[0], os.ReadFile)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, rom)
+}
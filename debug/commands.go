@@ -0,0 +1,424 @@
+package debug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// command is a debugger command typed in the command line.
+type command struct {
+	names []string
+	args  string
+	help  string
+	run   func(d *Debugger, args []string) (string, error)
+
+	// edits tells that the command changes the machine, which a read only
+	// debugger refuses
+	edits bool
+}
+
+var commands = []*command{
+	{
+		names: []string{"continue", "c"},
+		help:  "resume the machine",
+		run: func(d *Debugger, args []string) (string, error) {
+			d.Continue()
+			return "", nil
+		},
+	},
+	{
+		names: []string{"pause", "p"},
+		help:  "pause the machine",
+		run: func(d *Debugger, args []string) (string, error) {
+			d.Pause()
+			return "", nil
+		},
+	},
+	{
+		names: []string{"step", "s"},
+		args:  "[count]",
+		help:  "execute count instructions, 1 by default",
+		run: func(d *Debugger, args []string) (string, error) {
+			n, err := countArg(args, 1)
+			if err != nil {
+				return "", err
+			}
+			for ; n > 0; n-- {
+				if err := d.Step(); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		},
+	},
+	{
+		names: []string{"back", "bk"},
+		args:  "[count]",
+		help:  "undo count instructions, 1 by default",
+		run: func(d *Debugger, args []string) (string, error) {
+			n, err := countArg(args, 1)
+			if err != nil {
+				return "", err
+			}
+			for ; n > 0; n-- {
+				if err := d.StepBack(); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		},
+	},
+	{
+		names: []string{"rewind", "rw"},
+		args:  "[frames]",
+		help:  "undo the last frames, a second's worth by default",
+		run: func(d *Debugger, args []string) (string, error) {
+			n, err := countArg(args, chip8.TimerHz)
+			if err != nil {
+				return "", err
+			}
+			return "", d.Rewind(int(n))
+		},
+	},
+	{
+		names: []string{"next", "n"},
+		help:  "execute an instruction, running CALLs until they return",
+		run: func(d *Debugger, args []string) (string, error) {
+			return "", d.StepOver()
+		},
+	},
+	{
+		names: []string{"finish", "f"},
+		help:  "run until the current subroutine returns",
+		run: func(d *Debugger, args []string) (string, error) {
+			return "", d.StepOut()
+		},
+	},
+	{
+		names: []string{"until", "u"},
+		args:  "addr",
+		help:  "run until PC reaches addr",
+		run: func(d *Debugger, args []string) (string, error) {
+			addr, err := d.addrArg(args)
+			if err != nil {
+				return "", err
+			}
+			d.RunTo(addr)
+			return "", nil
+		},
+	},
+	{
+		names: []string{"break", "b"},
+		args:  "[addr]",
+		help:  "set a breakpoint at addr, or list the breakpoints",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) == 0 {
+				var addrs []string
+				for _, addr := range d.Breakpoints() {
+					addrs = append(addrs, fmt.Sprintf("%04X", addr))
+				}
+				if len(addrs) == 0 {
+					return "no breakpoints", nil
+				}
+				return "breakpoints: " + strings.Join(addrs, " "), nil
+			}
+			addr, err := d.addrArg(args)
+			if err != nil {
+				return "", err
+			}
+			d.SetBreakpoint(addr)
+			return fmt.Sprintf("breakpoint at %04X", addr), nil
+		},
+	},
+	{
+		names: []string{"delete", "d"},
+		args:  "[addr]",
+		help:  "delete the breakpoint at addr, or all of them",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) == 0 {
+				d.ClearBreakpoints()
+				return "deleted all breakpoints", nil
+			}
+			addr, err := d.addrArg(args)
+			if err != nil {
+				return "", err
+			}
+			if !d.ClearBreakpoint(addr) {
+				return "", fmt.Errorf("no breakpoint at %04X", addr)
+			}
+			return fmt.Sprintf("deleted breakpoint at %04X", addr), nil
+		},
+	},
+	{
+		names: []string{"watch", "w"},
+		args:  "[addr[-end] [r|w|rw]]",
+		help:  "pause when memory from addr to end is read or written, or list the watchpoints",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) == 0 {
+				var ws []string
+				for _, w := range d.Watchpoints() {
+					ws = append(ws, w.String())
+				}
+				if len(ws) == 0 {
+					return "no watchpoints", nil
+				}
+				return "watchpoints: " + strings.Join(ws, ", "), nil
+			}
+
+			w := Watchpoint{Access: ReadWrite}
+			start, end, isRange := strings.Cut(args[0], "-")
+			var err error
+			w.Start, err = ParseAddr(start)
+			if err != nil {
+				return "", err
+			}
+			w.End = w.Start
+			if isRange {
+				w.End, err = ParseAddr(end)
+				if err != nil {
+					return "", err
+				}
+				if w.End < w.Start {
+					return "", fmt.Errorf("empty range %s", args[0])
+				}
+			}
+			if len(args) > 1 {
+				switch strings.ToLower(args[1]) {
+				case "r":
+					w.Access = Read
+				case "w":
+					w.Access = Write
+				case "rw":
+				default:
+					return "", fmt.Errorf("invalid access %q, want r, w or rw", args[1])
+				}
+			}
+			d.Watch(w)
+			return "watchpoint " + w.String(), nil
+		},
+	},
+	{
+		names: []string{"unwatch"},
+		args:  "[addr]",
+		help:  "delete the watchpoints starting at addr, or all of them",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) == 0 {
+				d.ClearWatchpoints()
+				return "deleted all watchpoints", nil
+			}
+			addr, err := d.addrArg(args)
+			if err != nil {
+				return "", err
+			}
+			if !d.Unwatch(addr) {
+				return "", fmt.Errorf("no watchpoint at %04X", addr)
+			}
+			return fmt.Sprintf("deleted watchpoints at %04X", addr), nil
+		},
+	},
+	{
+		names: []string{"cond"},
+		args:  "[expr]",
+		help:  "pause when expr becomes true, like V3 == 10 && I > 300, or list the conditions",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) == 0 {
+				var cs []string
+				for i, e := range d.Conditions() {
+					cs = append(cs, fmt.Sprintf("%d: %s", i, e))
+				}
+				if len(cs) == 0 {
+					return "no conditions", nil
+				}
+				return "conditions: " + strings.Join(cs, ", "), nil
+			}
+			e, err := ParseExpr(strings.Join(args, " "))
+			if err != nil {
+				return "", err
+			}
+			d.AddCondition(e)
+			return fmt.Sprintf("condition %d: %s", len(d.Conditions())-1, e), nil
+		},
+	},
+	{
+		names: []string{"uncond"},
+		args:  "[n]",
+		help:  "delete the n-th condition, or all of them",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) == 0 {
+				d.ClearConditions()
+				return "deleted all conditions", nil
+			}
+			i, err := strconv.Atoi(args[0])
+			if err != nil || !d.RemoveCondition(i) {
+				return "", fmt.Errorf("no condition %s", args[0])
+			}
+			return "deleted condition " + args[0], nil
+		},
+	},
+	{
+		names: []string{"set"},
+		edits: true,
+		args:  "reg expr",
+		help:  "set V0 to VF, I, PC, DT or ST to the value of expr",
+		run: func(d *Debugger, args []string) (string, error) {
+			if len(args) < 2 {
+				return "", fmt.Errorf("usage: set reg expr")
+			}
+			e, err := ParseExpr(strings.Join(args[1:], " "))
+			if err != nil {
+				return "", err
+			}
+			v := e.Eval(d.m)
+
+			reg := strings.ToUpper(args[0])
+			switch reg {
+			case "I":
+				d.m.SetI(uint16(v))
+			case "PC":
+				d.m.SetPC(uint16(v))
+			case "DT":
+				d.m.SetDT(uint8(v))
+			case "ST":
+				d.m.SetST(uint8(v))
+			default:
+				x, err := strconv.ParseUint(strings.TrimPrefix(reg, "V"), 16, 4)
+				if err != nil || !strings.HasPrefix(reg, "V") {
+					return "", fmt.Errorf("invalid register %q", args[0])
+				}
+				d.m.SetV(uint8(x), uint8(v))
+				reg = fmt.Sprintf("V%X", x)
+			}
+			return fmt.Sprintf("%s = %X", reg, registers[reg](d.m)), nil
+		},
+	},
+	{
+		names: []string{"poke"},
+		edits: true,
+		args:  "addr byte...",
+		help:  "store the bytes in memory starting at addr",
+		run: func(d *Debugger, args []string) (string, error) {
+			addr, err := d.addrArg(args)
+			if err != nil {
+				return "", err
+			}
+			if len(args) < 2 {
+				return "", fmt.Errorf("missing bytes")
+			}
+			if int(addr)+len(args)-1 > 0x10000 {
+				return "", fmt.Errorf("bytes past the end of memory")
+			}
+			var bytes []uint8
+			for _, arg := range args[1:] {
+				b, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(arg), "0x"), 16, 8)
+				if err != nil {
+					return "", fmt.Errorf("invalid byte %q", arg)
+				}
+				bytes = append(bytes, uint8(b))
+			}
+			for i, b := range bytes {
+				d.m.Poke(addr+uint16(i), b)
+			}
+			return fmt.Sprintf("poked %d bytes at %04X", len(bytes), addr), nil
+		},
+	},
+}
+
+// help is added to the commands in init, as it refers to them.
+var help = &command{
+	names: []string{"help", "h"},
+	args:  "[command]",
+	help:  "describe a command, or list them",
+	run: func(d *Debugger, args []string) (string, error) {
+		if len(args) == 0 {
+			var names []string
+			for _, c := range commands {
+				names = append(names, c.names[0])
+			}
+			return "commands: " + strings.Join(names, " "), nil
+		}
+		c := lookup(args[0])
+		if c == nil {
+			return "", fmt.Errorf("unknown command %q", args[0])
+		}
+		usage := strings.Join(c.names, ", ")
+		if c.args != "" {
+			usage += " " + c.args
+		}
+		return usage + ": " + c.help, nil
+	},
+}
+
+func init() {
+	commands = append(commands, help)
+}
+
+func lookup(name string) *command {
+	for _, c := range commands {
+		for _, n := range c.names {
+			if n == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// Exec runs a command line, like "break 2A4" or "step 10", returning a
+// message for the user.
+func (d *Debugger) Exec(line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	c := lookup(strings.ToLower(fields[0]))
+	if c == nil {
+		return "", fmt.Errorf("unknown command %q, try help", fields[0])
+	}
+	if c.edits && d.readOnly {
+		return "", ErrReadOnly
+	}
+	return c.run(d, fields[1:])
+}
+
+// addrArg parses the address argument of a command, which can also be a
+// symbol or a label like L2A4.
+func (d *Debugger) addrArg(args []string) (uint16, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("missing address")
+	}
+	if addr, ok := d.symbols.Lookup(args[0]); ok {
+		return addr, nil
+	}
+	if rest, ok := strings.CutPrefix(strings.ToUpper(args[0]), "L"); ok {
+		if addr, err := ParseAddr(rest); err == nil {
+			return addr, nil
+		}
+	}
+	return ParseAddr(args[0])
+}
+
+// countArg parses the optional count argument of a command, in decimal.
+func countArg(args []string, def uint64) (uint64, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	n, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid count %q", args[0])
+	}
+	return n, nil
+}
+
+// ParseAddr parses an address written in hex, with or without 0x.
+func ParseAddr(s string) (uint16, error) {
+	hex := strings.TrimPrefix(strings.ToLower(s), "0x")
+	addr, err := strconv.ParseUint(hex, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %q", s)
+	}
+	return uint16(addr), nil
+}
@@ -0,0 +1,264 @@
+// Package debug implements a debugger for the chip8 virtual machine.
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// ErrNotInSubroutine is returned by StepOut when the stack is empty.
+var ErrNotInSubroutine = errors.New("not in a subroutine")
+
+// ErrNoHistory is returned by StepBack and Rewind when there are no
+// instructions recorded to undo.
+var ErrNoHistory = errors.New("no history to go back")
+
+// ErrReadOnly is returned by the commands changing registers or memory while
+// the debugger is read only.
+var ErrReadOnly = errors.New("the machine is read only")
+
+// Debugger runs a machine in place of its Frame method, stopping it on
+// breakpoints and executing it one instruction at a time while paused.
+type Debugger struct {
+	m *chip8.Machine
+
+	paused      bool
+	breakpoints map[uint16]bool
+	watchpoints []Watchpoint
+	conditions  []*Condition
+	symbols     Symbols
+	readOnly    bool
+
+	// why the machine paused, if not by the user
+	reason string
+
+	// why the machine should stop after the current instruction, set by
+	// the watchpoints and conditions
+	stop string
+
+	// until is the condition of a step over, step out or run to, which
+	// pauses the machine when it becomes true
+	until func() bool
+
+	// skipBreak lets the machine leave the breakpoint it is paused at
+	skipBreak bool
+
+	// values of the bytes written since the machine was resumed, from
+	// before they were first written
+	changes map[uint16]uint8
+}
+
+// New returns a debugger for m. The machine starts running.
+func New(m *chip8.Machine) *Debugger {
+	d := &Debugger{
+		m:           m,
+		breakpoints: map[uint16]bool{},
+		changes:     map[uint16]uint8{},
+	}
+	m.SetMemoryWatcher(memoryHook{d})
+	return d
+}
+
+// Machine returns the machine being debugged.
+func (d *Debugger) Machine() *chip8.Machine { return d.m }
+
+// Paused reports whether the machine is paused.
+func (d *Debugger) Paused() bool { return d.paused }
+
+// Reason returns why the machine paused, like "breakpoint at 0204", or ""
+// if it was paused by the user.
+func (d *Debugger) Reason() string { return d.reason }
+
+// Pause stops the machine before the next instruction.
+func (d *Debugger) Pause() {
+	d.pause("")
+}
+
+func (d *Debugger) pause(reason string) {
+	d.paused = true
+	d.until = nil
+	d.reason = reason
+}
+
+// Continue resumes the machine.
+func (d *Debugger) Continue() {
+	d.paused = false
+	d.until = nil
+	d.reason = ""
+	d.skipBreak = true
+	d.forgetChanges()
+}
+
+// SetReadOnly makes the commands changing registers or memory fail with
+// ErrReadOnly, for when the changes couldn't be reproduced, like in a movie.
+func (d *Debugger) SetReadOnly(ro bool) { d.readOnly = ro }
+
+// Changed reports whether an instruction changed the byte at addr since the
+// machine was last resumed or stepped.
+func (d *Debugger) Changed(addr uint16) bool {
+	old, ok := d.changes[addr]
+	return ok && d.m.Peek(addr) != old
+}
+
+func (d *Debugger) forgetChanges() {
+	if len(d.changes) > 0 {
+		d.changes = map[uint16]uint8{}
+	}
+}
+
+// SetBreakpoint makes the machine pause before executing addr.
+func (d *Debugger) SetBreakpoint(addr uint16) {
+	d.breakpoints[addr] = true
+}
+
+// ClearBreakpoint removes the breakpoint at addr, reporting whether there
+// was one.
+func (d *Debugger) ClearBreakpoint(addr uint16) bool {
+	ok := d.breakpoints[addr]
+	delete(d.breakpoints, addr)
+	return ok
+}
+
+// ClearBreakpoints removes all breakpoints.
+func (d *Debugger) ClearBreakpoints() {
+	d.breakpoints = map[uint16]bool{}
+}
+
+// IsBreakpoint reports whether there is a breakpoint at addr.
+func (d *Debugger) IsBreakpoint(addr uint16) bool {
+	return d.breakpoints[addr]
+}
+
+// Breakpoints returns the addresses of the breakpoints in order.
+func (d *Debugger) Breakpoints() []uint16 {
+	addrs := make([]uint16, 0, len(d.breakpoints))
+	for addr := range d.breakpoints {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+// Step pauses the machine and executes a single instruction. The timers
+// tick every IPF instructions, as they would if the frames were running.
+func (d *Debugger) Step() error {
+	d.Pause()
+	d.forgetChanges()
+	_, err := d.step()
+	if err == nil && d.stop != "" {
+		d.reason = d.stop
+	}
+	return err
+}
+
+// StepOver is like Step, but a CALL runs until the subroutine returns.
+func (d *Debugger) StepOver() error {
+	in := d.m.InstructionAt(d.m.PC())
+	if in.Op != chip8.OpCALL {
+		return d.Step()
+	}
+
+	ret, sp := d.m.PC()+in.Size(), d.m.SP()
+	d.Continue()
+	d.until = func() bool {
+		return d.m.PC() == ret && d.m.SP() == sp
+	}
+	return nil
+}
+
+// StepOut runs the machine until the current subroutine returns.
+func (d *Debugger) StepOut() error {
+	sp := d.m.SP()
+	if sp == 0 {
+		return ErrNotInSubroutine
+	}
+
+	d.Continue()
+	d.until = func() bool {
+		return d.m.SP() < sp
+	}
+	return nil
+}
+
+// RunTo runs the machine until PC reaches addr.
+func (d *Debugger) RunTo(addr uint16) {
+	d.Continue()
+	d.until = func() bool {
+		return d.m.PC() == addr
+	}
+}
+
+// StepBack pauses the machine and undoes the last instruction executed.
+// The machine must be recording its history with chip8.Machine.SetHistory.
+func (d *Debugger) StepBack() error {
+	if !d.m.StepBack() {
+		return ErrNoHistory
+	}
+	d.Pause()
+	d.forgetChanges()
+	return nil
+}
+
+// Rewind undoes the last n frames, or as many as there are in the history,
+// leaving the machine paused or running as it was.
+func (d *Debugger) Rewind(n int) error {
+	if d.m.Rewind(n) == 0 {
+		return ErrNoHistory
+	}
+	d.forgetChanges()
+	d.skipBreak = true
+	return nil
+}
+
+// Frame runs the machine for a frame, like chip8.Machine.Frame, unless it
+// is paused. The frame is cut short if the machine pauses during it, and
+// goes on from there when it is resumed. The machine pauses on errors.
+func (d *Debugger) Frame() error {
+	for !d.paused {
+		if d.breakpoints[d.m.PC()] && !d.skipBreak {
+			d.pause(fmt.Sprintf("breakpoint at %04X", d.m.PC()))
+			return nil
+		}
+		d.skipBreak = false
+
+		end, err := d.step()
+		if err != nil {
+			return err
+		}
+		if d.stop != "" {
+			d.pause(d.stop)
+			return nil
+		}
+		if d.until != nil && d.until() {
+			d.Pause()
+			return nil
+		}
+		if end {
+			return nil
+		}
+	}
+	return nil
+}
+
+// step executes an instruction, ticking the timers at the end of the frame.
+// It reports whether the frame ended, which is right away once the program
+// has exited, as no more instructions count towards it.
+func (d *Debugger) step() (bool, error) {
+	in := d.m.InstructionAt(d.m.PC())
+	d.stop = ""
+	err := d.m.Step()
+	if err != nil {
+		d.pause(err.Error())
+		return false, err
+	}
+	d.checkConditions()
+
+	if !d.m.Exited() && d.m.FrameSteps() < d.m.IPF() && !(d.m.Quirks().DisplayWait && in.Op == chip8.OpDRW) {
+		return false, nil
+	}
+	d.m.Tick()
+	return true, nil
+}
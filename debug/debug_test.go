@@ -0,0 +1,346 @@
+package debug
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func newTestDebugger(t *testing.T) *Debugger {
+	t.Helper()
+	m := chip8.New(chip8.Config{IPF: 4})
+	err := m.LoadROM([]byte{
+		0x60, 0x01, // 200: LD V0, 01
+		0x22, 0x08, // 202: CALL 0208
+		0x70, 0x01, // 204: ADD V0, 01
+		0x12, 0x06, // 206: JP 0206
+		0x61, 0x05, // 208: LD V1, 05
+		0x00, 0xEE, // 20A: RET
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(m)
+}
+
+func TestDebugger_breakpoints(t *testing.T) {
+	d := newTestDebugger(t)
+	m := d.Machine()
+	d.SetBreakpoint(0x20A)
+
+	if err := d.Frame(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Paused() || m.PC() != 0x20A {
+		t.Fatalf("want: paused at 020A, got: paused=%v PC=%04X", d.Paused(), m.PC())
+	}
+
+	if err := d.Frame(); err != nil {
+		t.Fatal(err)
+	}
+	if m.PC() != 0x20A {
+		t.Fatalf("want: no progress while paused, got: PC=%04X", m.PC())
+	}
+
+	d.Continue()
+	if err := d.Frame(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Paused() || m.PC() != 0x204 {
+		t.Fatalf("want: running past the breakpoint, got: paused=%v PC=%04X", d.Paused(), m.PC())
+	}
+}
+
+func TestDebugger_Step(t *testing.T) {
+	t.Run("step into", func(t *testing.T) {
+		d := newTestDebugger(t)
+		m := d.Machine()
+		d.Step()
+		d.Step()
+		if !d.Paused() || m.PC() != 0x208 {
+			t.Fatalf("want: paused at 0208, got: paused=%v PC=%04X", d.Paused(), m.PC())
+		}
+	})
+
+	t.Run("step over", func(t *testing.T) {
+		d := newTestDebugger(t)
+		m := d.Machine()
+		d.Step()
+		d.StepOver()
+		d.Frame()
+		if !d.Paused() || m.PC() != 0x204 || m.V(1) != 5 {
+			t.Fatalf("want: paused at 0204 with V1=05, got: paused=%v PC=%04X V1=%02X", d.Paused(), m.PC(), m.V(1))
+		}
+	})
+
+	t.Run("step out", func(t *testing.T) {
+		d := newTestDebugger(t)
+		m := d.Machine()
+		if err := d.StepOut(); !errors.Is(err, ErrNotInSubroutine) {
+			t.Fatalf("want: %v, got: %v", ErrNotInSubroutine, err)
+		}
+		d.Step()
+		d.Step()
+		d.StepOut()
+		d.Frame()
+		if !d.Paused() || m.PC() != 0x204 {
+			t.Fatalf("want: paused at 0204, got: paused=%v PC=%04X", d.Paused(), m.PC())
+		}
+	})
+
+	t.Run("run to", func(t *testing.T) {
+		d := newTestDebugger(t)
+		m := d.Machine()
+		d.RunTo(0x20A)
+		d.Frame()
+		if !d.Paused() || m.PC() != 0x20A {
+			t.Fatalf("want: paused at 020A, got: paused=%v PC=%04X", d.Paused(), m.PC())
+		}
+	})
+
+	t.Run("frame", func(t *testing.T) {
+		// the steps count towards the frame that was interrupted
+		d := newTestDebugger(t)
+		m := d.Machine()
+		for i := 0; i < 3; i++ {
+			d.Step()
+		}
+		d.Continue()
+		d.Frame()
+		if m.PC() != 0x204 {
+			t.Fatalf("want: frame ended after IPF instructions, got: PC=%04X", m.PC())
+		}
+	})
+}
+
+func TestDebugger_Exec(t *testing.T) {
+	d := newTestDebugger(t)
+	m := d.Machine()
+
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{"b 0x20a", "breakpoint at 020A", false},
+		{"break 204", "breakpoint at 0204", false},
+		{"break", "breakpoints: 0204 020A", false},
+		{"d 300", "", true},
+		{"delete 204", "deleted breakpoint at 0204", false},
+		{"break zz", "", true},
+		{"step 2", "", false},
+		{"jump", "", true},
+		{"help next", "next, n: execute an instruction, running CALLs until they return", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := d.Exec(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want: error=%v, got: %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("want: %q, got: %q", tt.want, got)
+			}
+		})
+	}
+
+	if m.PC() != 0x208 {
+		t.Fatalf("want: PC=0208 after stepping, got: %04X", m.PC())
+	}
+}
+
+func TestDebugger_watchpoints(t *testing.T) {
+	newDebugger := func(t *testing.T) *Debugger {
+		m := chip8.New(chip8.Config{})
+		err := m.LoadROM([]byte{
+			0xA3, 0x00, // 200: LD I, 0300
+			0x60, 0x7B, // 202: LD V0, 7B
+			0xF0, 0x33, // 204: LD B, V0
+			0xF2, 0x65, // 206: LD V2, [I]
+			0xD0, 0x05, // 208: DRW V0, V0, 5
+			0x12, 0x0A, // 20A: JP 020A
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return New(m)
+	}
+
+	tests := []struct {
+		name string
+		w    Watchpoint
+		pc   uint16
+	}{
+		{"write", Watchpoint{0x302, 0x302, Write}, 0x206},
+		{"read", Watchpoint{0x300, 0x310, Read}, 0x208},
+		{"read by draw", Watchpoint{0x303, 0x304, ReadWrite}, 0x20A},
+		{"outside", Watchpoint{0x305, 0x310, ReadWrite}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDebugger(t)
+			d.Watch(tt.w)
+			d.Frame()
+			if tt.pc == 0 {
+				if d.Paused() {
+					t.Fatalf("want: running, got: paused at %04X: %s", d.Machine().PC(), d.Reason())
+				}
+				return
+			}
+			if !d.Paused() || d.Machine().PC() != tt.pc {
+				t.Fatalf("want: paused at %04X, got: paused=%v PC=%04X", tt.pc, d.Paused(), d.Machine().PC())
+			}
+			if d.Reason() == "" {
+				t.Fatal("want: a reason for the pause, got: none")
+			}
+		})
+	}
+}
+
+func TestDebugger_conditions(t *testing.T) {
+	m := chip8.New(chip8.Config{IPF: 100})
+	err := m.LoadROM([]byte{
+		0x70, 0x01, // 200: ADD V0, 01
+		0x12, 0x00, // 202: JP 0200
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(m)
+
+	e, err := ParseExpr("V0 & 3 == 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.AddCondition(e)
+
+	for _, want := range []uint8{4, 8} {
+		d.Continue()
+		d.Frame()
+		if !d.Paused() || m.V(0) != want {
+			t.Fatalf("want: paused with V0=%02X, got: paused=%v V0=%02X", want, d.Paused(), m.V(0))
+		}
+	}
+}
+
+func TestDebugger_edit(t *testing.T) {
+	d := newTestDebugger(t)
+	m := d.Machine()
+
+	for _, line := range []string{"set V3 V3 + 1F", "set i 300", "set PC 204", "poke 300 AB CD"} {
+		if _, err := d.Exec(line); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+	}
+	if m.V(3) != 0x1F || m.I() != 0x300 || m.PC() != 0x204 || m.Peek(0x300) != 0xAB || m.Peek(0x301) != 0xCD {
+		t.Fatalf("want: V3=1F I=0300 PC=0204 [0300]=AB CD, got: V3=%02X I=%04X PC=%04X [0300]=%02X %02X",
+			m.V(3), m.I(), m.PC(), m.Peek(0x300), m.Peek(0x301))
+	}
+	if out, err := d.Exec("set V03 5"); err != nil || out != "V3 = 5" || m.V(3) != 5 {
+		t.Fatalf("set V03 5: want: V3 = 5, got: %q, %v, V3=%02X", out, err, m.V(3))
+	}
+	d.SetReadOnly(true)
+	for _, line := range []string{"set V3 1", "poke 300 1"} {
+		if _, err := d.Exec(line); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("%s: want: %v, got: %v", line, ErrReadOnly, err)
+		}
+	}
+	if m.V(3) != 5 || m.Peek(0x300) != 0xAB {
+		t.Fatalf("want: no edits while read only, got: V3=%02X [0300]=%02X", m.V(3), m.Peek(0x300))
+	}
+	d.SetReadOnly(false)
+
+	for _, line := range []string{"set VG 1", "set V3", "poke 300", "poke FFFF 1 2", "poke 300 100"} {
+		if _, err := d.Exec(line); err == nil {
+			t.Fatalf("%s: want: error, got: nil", line)
+		}
+	}
+}
+
+func TestDebugger_Changed(t *testing.T) {
+	m := chip8.New(chip8.Config{})
+	err := m.LoadROM([]byte{
+		0xA3, 0x00, // 200: LD I, 0300
+		0x60, 0x7B, // 202: LD V0, 7B
+		0xF0, 0x33, // 204: LD B, V0
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(m)
+	m.Poke(0x302, 3)
+
+	d.Step()
+	d.Step()
+	d.Step()
+	for addr, want := range map[uint16]bool{0x300: true, 0x301: true, 0x302: false, 0x303: false} {
+		if got := d.Changed(addr); got != want {
+			t.Fatalf("want: changed=%v at %04X, got: %v", want, addr, got)
+		}
+	}
+
+	d.Step()
+	if d.Changed(0x300) {
+		t.Fatal("want: changes forgotten after a step, got: 0300 changed")
+	}
+}
+
+func TestDebugger_StepBack(t *testing.T) {
+	d := newTestDebugger(t)
+	m := d.Machine()
+	if err := d.StepBack(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("want: %v without history, got: %v", ErrNoHistory, err)
+	}
+
+	m.SetHistory(chip8.NewHistory(100))
+	d.Frame()
+	d.Frame()
+	if _, err := d.Exec("back 6"); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Paused() || m.PC() != 0x208 || m.SP() != 1 || m.FrameSteps() != 2 {
+		t.Fatalf("want: paused at 0208 in the CALL, got: paused=%v PC=%04X SP=%d", d.Paused(), m.PC(), m.SP())
+	}
+
+	// the steps go on from the middle of the frame
+	d.Step()
+	d.Step()
+	if m.FrameSteps() != 0 || m.PC() != 0x204 {
+		t.Fatalf("want: frame ended at 0204, got: %d steps at %04X", m.FrameSteps(), m.PC())
+	}
+
+	if _, err := d.Exec("rewind"); err != nil {
+		t.Fatal(err)
+	}
+	if m.PC() != 0x200 || m.V(0) != 0 {
+		t.Fatalf("want: rewound to the start, got: PC=%04X V0=%02X", m.PC(), m.V(0))
+	}
+}
+
+func TestDebugger_exit(t *testing.T) {
+	m := chip8.New(chip8.Config{IPF: 4})
+	err := m.LoadROM([]byte{
+		0x60, 0x05, // 200: LD V0, 05
+		0xF0, 0x15, // 202: LD DT, V0
+		0x00, 0xFD, // 204: EXIT
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(m)
+
+	for i := 0; i < 3; i++ {
+		if err := d.Frame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !m.Exited() || d.Paused() {
+		t.Fatalf("want: exited and running, got: exited=%v paused=%v", m.Exited(), d.Paused())
+	}
+	if m.DT() != 2 {
+		t.Fatalf("want: DT=2 after a tick per frame, got: DT=%d", m.DT())
+	}
+}
@@ -0,0 +1,234 @@
+package debug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// Expr is an expression over the state of the machine, like
+// "V3 == 10 && I > 300". Numbers are in hex, like everywhere else in the
+// debugger, with an optional 0x prefix.
+//
+// The operands are numbers, the registers V0 to VF, I, PC, SP, DT and ST,
+// and [addr] for the byte stored at addr. The operators are, from the
+// lowest precedence to the highest:
+//
+//	||
+//	&&
+//	== != < <= > >=
+//	+ - & |
+//	! (unary)
+//
+// Comparisons and logical operators result in 1 when true and 0 when false.
+type Expr struct {
+	src  string
+	eval func(m *chip8.Machine) int
+}
+
+// ParseExpr parses an expression.
+func ParseExpr(src string) (*Expr, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	eval, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q", p.toks[p.pos])
+	}
+	return &Expr{src: src, eval: eval}, nil
+}
+
+// Eval evaluates the expression on m.
+func (e *Expr) Eval(m *chip8.Machine) int { return e.eval(m) }
+
+// String returns the source of the expression.
+func (e *Expr) String() string { return e.src }
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "+", "-", "&", "|", "!", "(", ")", "[", "]"}
+
+func tokenize(src string) ([]string, error) {
+	var toks []string
+	rest := src
+outer:
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return toks, nil
+		}
+
+		for _, op := range operators {
+			if strings.HasPrefix(rest, op) {
+				toks = append(toks, op)
+				rest = rest[len(op):]
+				continue outer
+			}
+		}
+
+		n := strings.IndexFunc(rest, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if n == 0 {
+			return nil, fmt.Errorf("unexpected %q", rest[:1])
+		}
+		if n < 0 {
+			n = len(rest)
+		}
+		toks = append(toks, rest[:n])
+		rest = rest[n:]
+	}
+}
+
+type evalFunc = func(m *chip8.Machine) int
+
+type parser struct {
+	toks []string
+	pos  int
+}
+
+func (p *parser) peek() string {
+	if p.pos == len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(tok string) error {
+	got := p.next()
+	if got == "" {
+		return fmt.Errorf("missing %q", tok)
+	}
+	if got != tok {
+		return fmt.Errorf("want %q, got %q", tok, got)
+	}
+	return nil
+}
+
+// binary parses operands separated by the operators in ops, which are all
+// of the same precedence and left associative.
+func (p *parser) binary(operand func() (evalFunc, error), ops map[string]func(a, b int) int) (evalFunc, error) {
+	a, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		f, ok := ops[p.peek()]
+		if !ok {
+			return a, nil
+		}
+		p.next()
+		b, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		a = func(a, b evalFunc) evalFunc {
+			return func(m *chip8.Machine) int { return f(a(m), b(m)) }
+		}(a, b)
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var (
+	orOps = map[string]func(a, b int) int{
+		"||": func(a, b int) int { return boolInt(a != 0 || b != 0) },
+	}
+	andOps = map[string]func(a, b int) int{
+		"&&": func(a, b int) int { return boolInt(a != 0 && b != 0) },
+	}
+	cmpOps = map[string]func(a, b int) int{
+		"==": func(a, b int) int { return boolInt(a == b) },
+		"!=": func(a, b int) int { return boolInt(a != b) },
+		"<":  func(a, b int) int { return boolInt(a < b) },
+		"<=": func(a, b int) int { return boolInt(a <= b) },
+		">":  func(a, b int) int { return boolInt(a > b) },
+		">=": func(a, b int) int { return boolInt(a >= b) },
+	}
+	sumOps = map[string]func(a, b int) int{
+		"+": func(a, b int) int { return a + b },
+		"-": func(a, b int) int { return a - b },
+		"&": func(a, b int) int { return a & b },
+		"|": func(a, b int) int { return a | b },
+	}
+)
+
+func (p *parser) or() (evalFunc, error)  { return p.binary(p.and, orOps) }
+func (p *parser) and() (evalFunc, error) { return p.binary(p.cmp, andOps) }
+func (p *parser) cmp() (evalFunc, error) { return p.binary(p.sum, cmpOps) }
+func (p *parser) sum() (evalFunc, error) { return p.binary(p.unary, sumOps) }
+
+func (p *parser) unary() (evalFunc, error) {
+	if p.peek() != "!" {
+		return p.operand()
+	}
+	p.next()
+	a, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(m *chip8.Machine) int { return boolInt(a(m) == 0) }, nil
+}
+
+var registers = map[string]evalFunc{
+	"I":  func(m *chip8.Machine) int { return int(m.I()) },
+	"PC": func(m *chip8.Machine) int { return int(m.PC()) },
+	"SP": func(m *chip8.Machine) int { return int(m.SP()) },
+	"DT": func(m *chip8.Machine) int { return int(m.DT()) },
+	"ST": func(m *chip8.Machine) int { return int(m.ST()) },
+}
+
+func init() {
+	for x := 0; x <= 0xF; x++ {
+		x := uint8(x)
+		registers[fmt.Sprintf("V%X", x)] = func(m *chip8.Machine) int { return int(m.V(x)) }
+	}
+}
+
+func (p *parser) operand() (evalFunc, error) {
+	tok := p.next()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("missing operand")
+	case "(":
+		a, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return a, p.expect(")")
+	case "[":
+		addr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return func(m *chip8.Machine) int { return int(m.Peek(uint16(addr(m)))) }, p.expect("]")
+	}
+
+	if reg, ok := registers[strings.ToUpper(tok)]; ok {
+		return reg, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(tok), "0x"), 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	return func(*chip8.Machine) int { return int(n) }, nil
+}
@@ -0,0 +1,59 @@
+package debug
+
+import (
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func TestParseExpr(t *testing.T) {
+	m := chip8.New(chip8.Config{})
+	err := m.LoadROM([]byte{
+		0x63, 0x10, // LD V3, 10
+		0xA3, 0x20, // LD I, 0320
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Step()
+	m.Step()
+
+	tests := []struct {
+		src     string
+		want    int
+		wantErr bool
+	}{
+		{"V3 == 0x10 && I > 0x300", 1, false},
+		{"v3 == 10 && i > 300", 1, false},
+		{"V3 == 0x10 && I > 0x320", 0, false},
+		{"V3 != 10 || PC == 204", 1, false},
+		{"!(V3 == 10)", 0, false},
+		{"I - 20 == 300", 1, false},
+		{"V3 & F0", 0x10, false},
+		{"[PC - 2] == A3", 1, false},
+		{"SP + DT + ST", 0, false},
+		{"1 + 2 == 3 == 1", 1, false},
+		{"", 0, true},
+		{"V3 ==", 0, true},
+		{"(V3", 0, true},
+		{"V3 = 10", 0, true},
+		{"VG", 0, true},
+		{"V3 10", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := ParseExpr(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want: error=%v, got: %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			got := e.Eval(m)
+			if got != tt.want {
+				t.Fatalf("want: %X, got: %X", tt.want, got)
+			}
+		})
+	}
+}
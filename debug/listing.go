@@ -0,0 +1,132 @@
+package debug
+
+import (
+	"fmt"
+
+	"github.com/igoracmelo/ch8/asm"
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// Line is an instruction of a listing.
+type Line struct {
+	Addr        uint16
+	Instruction chip8.Instruction
+}
+
+// String returns the assembly of the instruction, or a db directive with
+// its bytes if it is invalid.
+func (l Line) String() string {
+	if l.Instruction.Op == chip8.OpInvalid {
+		return fmt.Sprintf("db %02X, %02X", l.Instruction.Opcode>>8, l.Instruction.Opcode&0xFF)
+	}
+	return l.Instruction.String()
+}
+
+// Listing is the disassembly of the program in memory, decoded linearly
+// from ProgramStart to the end of the ROM. Data mixed with the code is
+// shown as whatever instructions it decodes to.
+type Listing struct {
+	m      *chip8.Machine
+	lines  []Line
+	index  map[uint16]int
+	labels map[uint16]string
+}
+
+// NewListing disassembles the program in the memory of m. The addresses
+// jumped to or called are labeled, with the names in syms if there are
+// any. The listing doesn't change with the memory, so it should be made
+// again after the program modifies itself.
+func NewListing(m *chip8.Machine, syms Symbols) *Listing {
+	l := &Listing{
+		m:      m,
+		index:  map[uint16]int{},
+		labels: map[uint16]string{},
+	}
+	for addr, name := range syms {
+		l.labels[addr] = name
+	}
+
+	end := chip8.ProgramStart + m.ROMSize()
+	for addr := chip8.ProgramStart; addr < end; {
+		in := m.InstructionAt(uint16(addr))
+		l.index[uint16(addr)] = len(l.lines)
+		l.lines = append(l.lines, Line{Addr: uint16(addr), Instruction: in})
+		switch in.Op {
+		case chip8.OpJP, chip8.OpCALL:
+			if _, ok := l.labels[in.Addr]; !ok {
+				l.labels[in.Addr] = asm.Label(in.Addr)
+			}
+		}
+		addr += int(in.Size())
+	}
+	return l
+}
+
+// Label returns the label of addr, or "" if nothing jumps to it and it
+// has no symbol.
+func (l *Listing) Label(addr uint16) string {
+	return l.labels[addr]
+}
+
+// Len returns the number of lines in the listing.
+func (l *Listing) Len() int { return len(l.lines) }
+
+// Window returns the lines around addr: up to before lines before it, the
+// line at addr and up to after lines after it. It also returns the index of
+// the line at addr in the window.
+//
+// Addresses out of the listing, like when the program runs code it wrote
+// elsewhere, are disassembled on the spot assuming the instructions before
+// them are aligned to 2 bytes.
+func (l *Listing) Window(addr uint16, before, after int) ([]Line, int) {
+	if i, ok := l.index[addr]; ok {
+		start := i - before
+		if start < 0 {
+			start = 0
+		}
+		end := i + after + 1
+		if end > len(l.lines) {
+			end = len(l.lines)
+		}
+		return l.lines[start:end], i - start
+	}
+
+	var lines []Line
+	cur := -1
+	start := int(addr) - 2*before
+	if start < 0 {
+		start = int(addr) % 2
+	}
+	for a := start; a < 0x10000 && (cur < 0 || len(lines)-cur <= after); {
+		if a > int(addr) && cur < 0 {
+			// an instruction ended past addr, so start again from it
+			lines, a = nil, int(addr)
+			continue
+		}
+		if a == int(addr) {
+			cur = len(lines)
+		}
+		in := l.m.InstructionAt(uint16(a))
+		lines = append(lines, Line{Addr: uint16(a), Instruction: in})
+		a += int(in.Size())
+	}
+	return lines, cur
+}
+
+// Prev returns the address of the line before addr.
+func (l *Listing) Prev(addr uint16) uint16 {
+	lines, i := l.Window(addr, 1, 0)
+	if i > 0 {
+		return lines[i-1].Addr
+	}
+	return addr
+}
+
+// Next returns the address of the line after addr.
+func (l *Listing) Next(addr uint16) uint16 {
+	lines, i := l.Window(addr, 0, 1)
+	if i+1 < len(lines) {
+		return lines[i+1].Addr
+	}
+	return addr
+}
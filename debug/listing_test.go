@@ -0,0 +1,52 @@
+package debug
+
+import (
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func TestListing(t *testing.T) {
+	m := chip8.New(chip8.Config{})
+	err := m.LoadROM([]byte{
+		0x22, 0x08, // 200: CALL 0208
+		0xF0, 0x00, // 202: LD I, LONG 0300
+		0x03, 0x00,
+		0x12, 0x02, // 206: JP 0202
+		0xE0, 0x00, // 208: db E0, 00
+		0x00, 0xEE, // 20A: RET
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewListing(m, Symbols{0x20A: "ret"})
+
+	if l.Len() != 5 {
+		t.Fatalf("want: 5 lines, got: %d", l.Len())
+	}
+	for addr, want := range map[uint16]string{0x202: "L202", 0x208: "L208", 0x206: "", 0x20A: "ret"} {
+		if got := l.Label(addr); got != want {
+			t.Fatalf("want: label %q at %04X, got: %q", want, addr, got)
+		}
+	}
+
+	lines, i := l.Window(0x206, 1, 10)
+	if len(lines) != 4 || i != 1 || lines[i].Addr != 0x206 {
+		t.Fatalf("want: 4 lines with 0206 at 1, got: %v at %d", lines, i)
+	}
+	if got := lines[2].String(); got != "db E0, 00" {
+		t.Fatalf("want: db E0, 00, got: %q", got)
+	}
+
+	lines, i = l.Window(0x301, 2, 1)
+	if len(lines) != 4 || i != 2 || lines[0].Addr != 0x2FD || lines[i].Addr != 0x301 {
+		t.Fatalf("want: 4 lines from 02FD with 0301 at 2, got: %v at %d", lines, i)
+	}
+
+	if got := l.Prev(0x206); got != 0x202 {
+		t.Fatalf("want: 0202 before 0206, got: %04X", got)
+	}
+	if got := l.Next(0x202); got != 0x206 {
+		t.Fatalf("want: 0206 after 0202, got: %04X", got)
+	}
+}
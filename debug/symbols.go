@@ -0,0 +1,94 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/igoracmelo/ch8/asm"
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// Symbols names addresses of the program.
+type Symbols map[uint16]string
+
+// ReadSymbols reads symbols from r, one per line as an address in hex
+// followed by its name, like "2A4 draw_player". Blank lines and lines
+// starting with ; or # are ignored.
+func ReadSymbols(r io.Reader) (Symbols, error) {
+	syms := Symbols{}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want address and name, got %q", n, line)
+		}
+		addr, err := ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		syms[addr] = fields[1]
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return syms, nil
+}
+
+// Lookup returns the address of the symbol called name.
+func (s Symbols) Lookup(name string) (uint16, bool) {
+	for addr, n := range s {
+		if n == name {
+			return addr, true
+		}
+	}
+	return 0, false
+}
+
+// SetSymbols sets the names given to addresses.
+func (d *Debugger) SetSymbols(s Symbols) { d.symbols = s }
+
+// Symbols returns the names given to addresses.
+func (d *Debugger) Symbols() Symbols { return d.symbols }
+
+// Symbol returns the name of addr: its symbol, or its label if there is no
+// symbol for it.
+func (d *Debugger) Symbol(addr uint16) string {
+	if name, ok := d.symbols[addr]; ok {
+		return name
+	}
+	return asm.Label(addr)
+}
+
+// CallFrame is a subroutine being executed.
+type CallFrame struct {
+	// Return is the address the subroutine returns to.
+	Return uint16
+
+	// Target is the address of the subroutine, taken from the CALL before
+	// Return. It is only valid if Called is true, which it isn't when the
+	// stack was changed by other means.
+	Target uint16
+	Called bool
+}
+
+// CallStack returns the subroutines being executed, innermost first.
+func CallStack(m *chip8.Machine) []CallFrame {
+	stack := m.Stack()
+	frames := make([]CallFrame, 0, m.SP())
+	for i := int(m.SP()) - 1; i >= 0; i-- {
+		f := CallFrame{Return: stack[i]}
+		in := m.InstructionAt(f.Return - 2)
+		if in.Op == chip8.OpCALL {
+			f.Target, f.Called = in.Addr, true
+		}
+		frames = append(frames, f)
+	}
+	return frames
+}
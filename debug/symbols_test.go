@@ -0,0 +1,48 @@
+package debug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSymbols(t *testing.T) {
+	syms, err := ReadSymbols(strings.NewReader("; symbols\n208 set_v1\n\n0x20A  done\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syms[0x208] != "set_v1" || syms[0x20A] != "done" || len(syms) != 2 {
+		t.Fatalf("want: set_v1 at 0208 and done at 020A, got: %v", syms)
+	}
+
+	_, err = ReadSymbols(strings.NewReader("208 set_v1\n20A\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("want: error in line 2, got: %v", err)
+	}
+
+	d := newTestDebugger(t)
+	d.SetSymbols(syms)
+	if _, err := d.Exec("break set_v1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Exec("break L204"); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Breakpoints(); len(got) != 2 || got[0] != 0x204 || got[1] != 0x208 {
+		t.Fatalf("want: breakpoints at 0204 and 0208, got: %v", got)
+	}
+}
+
+func TestCallStack(t *testing.T) {
+	d := newTestDebugger(t)
+	m := d.Machine()
+	if got := CallStack(m); len(got) != 0 {
+		t.Fatalf("want: empty call stack, got: %v", got)
+	}
+
+	d.Step()
+	d.Step()
+	want := CallFrame{Return: 0x204, Target: 0x208, Called: true}
+	if got := CallStack(m); len(got) != 1 || got[0] != want {
+		t.Fatalf("want: %+v, got: %+v", want, got)
+	}
+}
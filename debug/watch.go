@@ -0,0 +1,153 @@
+package debug
+
+import (
+	"fmt"
+)
+
+// Access is the kind of memory access a watchpoint stops on.
+type Access uint8
+
+const (
+	Read Access = 1 << iota
+	Write
+
+	ReadWrite = Read | Write
+)
+
+func (a Access) String() string {
+	switch a {
+	case Read:
+		return "r"
+	case Write:
+		return "w"
+	}
+	return "rw"
+}
+
+// Watchpoint pauses the machine after an instruction accesses memory from
+// Start to End, inclusive.
+type Watchpoint struct {
+	Start, End uint16
+	Access     Access
+}
+
+func (w Watchpoint) String() string {
+	if w.Start == w.End {
+		return fmt.Sprintf("%04X %s", w.Start, w.Access)
+	}
+	return fmt.Sprintf("%04X-%04X %s", w.Start, w.End, w.Access)
+}
+
+// Condition pauses the machine after an instruction makes its expression
+// true. It doesn't pause again until the expression becomes false and then
+// true again.
+type Condition struct {
+	Expr *Expr
+
+	// value of the expression after the last instruction
+	last bool
+}
+
+// Watch adds a watchpoint.
+func (d *Debugger) Watch(w Watchpoint) {
+	d.watchpoints = append(d.watchpoints, w)
+}
+
+// Unwatch removes the watchpoints starting at addr, reporting whether there
+// were any.
+func (d *Debugger) Unwatch(addr uint16) bool {
+	n := len(d.watchpoints)
+	kept := d.watchpoints[:0]
+	for _, w := range d.watchpoints {
+		if w.Start != addr {
+			kept = append(kept, w)
+		}
+	}
+	d.watchpoints = kept
+	return len(kept) < n
+}
+
+// ClearWatchpoints removes all watchpoints.
+func (d *Debugger) ClearWatchpoints() {
+	d.watchpoints = nil
+}
+
+// Watchpoints returns the watchpoints in the order they were added.
+func (d *Debugger) Watchpoints() []Watchpoint {
+	return append([]Watchpoint(nil), d.watchpoints...)
+}
+
+// AddCondition adds a condition on e. It starts as false, so it pauses the
+// machine after the next instruction if e is already true.
+func (d *Debugger) AddCondition(e *Expr) {
+	d.conditions = append(d.conditions, &Condition{Expr: e})
+}
+
+// RemoveCondition removes the i-th condition, counting from 0.
+func (d *Debugger) RemoveCondition(i int) bool {
+	if i < 0 || i >= len(d.conditions) {
+		return false
+	}
+	d.conditions = append(d.conditions[:i], d.conditions[i+1:]...)
+	return true
+}
+
+// ClearConditions removes all conditions.
+func (d *Debugger) ClearConditions() {
+	d.conditions = nil
+}
+
+// Conditions returns the expressions of the conditions in the order they
+// were added.
+func (d *Debugger) Conditions() []*Expr {
+	exprs := make([]*Expr, len(d.conditions))
+	for i, c := range d.conditions {
+		exprs[i] = c.Expr
+	}
+	return exprs
+}
+
+// memoryHook is the chip8.MemoryWatcher of the debugger, recording the
+// bytes changed and which watchpoint the current instruction triggered.
+type memoryHook struct {
+	d *Debugger
+}
+
+func (h memoryHook) Access(addr uint16, n int, write bool) {
+	d := h.d
+	if write {
+		for i := 0; i < n; i++ {
+			a := addr + uint16(i)
+			if _, ok := d.changes[a]; !ok {
+				d.changes[a] = d.m.Peek(a)
+			}
+		}
+	}
+	if n == 0 || d.stop != "" {
+		return
+	}
+
+	access, verb := Read, "read"
+	if write {
+		access, verb = Write, "write"
+	}
+	end := int(addr) + n - 1
+	for _, w := range d.watchpoints {
+		if w.Access&access != 0 && int(w.Start) <= end && int(addr) <= int(w.End) {
+			d.stop = fmt.Sprintf("watchpoint %s: %s of %04X-%04X", w, verb, addr, end)
+			return
+		}
+	}
+}
+
+// checkConditions updates the conditions after an instruction, telling the
+// machine to stop when one becomes true.
+func (d *Debugger) checkConditions() {
+	for _, c := range d.conditions {
+		v := c.Expr.Eval(d.m) != 0
+		if v && !c.last && d.stop == "" {
+			d.stop = "condition: " + c.Expr.String()
+		}
+		c.last = v
+	}
+}
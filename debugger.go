@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/chip8"
+	"github.com/igoracmelo/ch8/debug"
+)
+
+// debugHelp lists the hotkeys, shown in the status line.
+const debugHelp = "F2 save  F3 load  F10 record  F12 screenshot  F5 pause/continue  F6 step  Shift-F6 step back  F7 step over  F8 step out  F11 rewind  : command"
+
+// rewindFrames is how far back F11 goes, 3 seconds.
+const rewindFrames = 3 * chip8.TimerHz
+
+// debugKey runs the debugger action bound to the key, reporting whether
+// there is one.
+func debugKey(d *debug.Debugger, ev *tcell.EventKey) (bool, error) {
+	switch ev.Key() {
+	case tcell.KeyF5:
+		if d.Paused() {
+			d.Continue()
+		} else {
+			d.Pause()
+		}
+		return true, nil
+	case tcell.KeyF6:
+		if ev.Modifiers()&tcell.ModShift != 0 {
+			return true, d.StepBack()
+		}
+		return true, d.Step()
+	case tcell.KeyF18:
+		// Shift-F6 in terminals without modifiers
+		return true, d.StepBack()
+	case tcell.KeyF7:
+		return true, d.StepOver()
+	case tcell.KeyF8:
+		return true, d.StepOut()
+	case tcell.KeyF11:
+		return true, d.Rewind(rewindFrames)
+	}
+	return false, nil
+}
+
+// cmdline is the debugger command line at the bottom of the screen. While
+// editing it takes all the keys, so they don't reach the keypad.
+type cmdline struct {
+	editing bool
+	text    []rune
+
+	// result of the last command
+	msg   string
+	isErr bool
+}
+
+// key edits the command line, returning the line typed when Enter is
+// pressed.
+func (c *cmdline) key(ev *tcell.EventKey) (string, bool) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		line := string(c.text)
+		c.editing = false
+		c.text = c.text[:0]
+		return line, true
+	case tcell.KeyEscape:
+		c.editing = false
+		c.text = c.text[:0]
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(c.text) > 0 {
+			c.text = c.text[:len(c.text)-1]
+		} else {
+			c.editing = false
+		}
+	case tcell.KeyRune:
+		c.text = append(c.text, ev.Rune())
+	}
+	return "", false
+}
+
+// report shows the result of a command.
+func (c *cmdline) report(msg string, err error) {
+	c.msg, c.isErr = msg, false
+	if err != nil {
+		c.msg, c.isErr = err.Error(), true
+	}
+}
+
+// draw draws the status of the debugger and the command line in the last
+// two rows of the screen.
+func (c *cmdline) draw(scr tcell.Screen, d *debug.Debugger) {
+	w, h := scr.Size()
+	blank := func(y int) {
+		for x := 0; x < w; x++ {
+			scr.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+	}
+	text := func(y int, s string, style tcell.Style) {
+		for i, r := range []rune(s) {
+			scr.SetContent(i, y, r, nil, style)
+		}
+	}
+
+	blank(h - 2)
+	status := "running"
+	if d.Paused() {
+		status = fmt.Sprintf("paused at %04X", d.Machine().PC())
+	}
+	// the reason the machine paused replaces the help
+	info, style := debugHelp, tcell.StyleDefault.Foreground(tcell.ColorGray)
+	if d.Reason() != "" {
+		info, style = d.Reason(), tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	}
+	text(h-2, fmt.Sprintf("%-16s %s", status, info), style)
+
+	blank(h - 1)
+	switch {
+	case c.editing:
+		text(h-1, ":"+string(c.text), tcell.StyleDefault)
+		scr.ShowCursor(len(c.text)+1, h-1)
+	case c.isErr:
+		scr.HideCursor()
+		text(h-1, c.msg, tcell.StyleDefault.Foreground(tcell.ColorRed))
+	default:
+		scr.HideCursor()
+		text(h-1, c.msg, tcell.StyleDefault)
+	}
+}
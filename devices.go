@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/audio"
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// palette are the colors of the pixels, by the planes they are on: none,
+// first, second and both.
+var palette = [4]tcell.Color{
+	tcell.ColorBlack,
+	tcell.ColorWhite,
+	tcell.ColorOrangeRed,
+	tcell.ColorGray,
+}
+
+// parsePalette parses the -palette flag, the 4 colors of palette in hex
+// separated by commas, like "000000,FFFFFF,FF4500,808080".
+func parsePalette(s string) ([4]tcell.Color, error) {
+	var p [4]tcell.Color
+	colors := strings.Split(s, ",")
+	if len(colors) != len(p) {
+		return p, fmt.Errorf("want %d colors, got %d", len(p), len(colors))
+	}
+	for i, c := range colors {
+		c = strings.TrimPrefix(strings.TrimSpace(c), "#")
+		rgb, err := strconv.ParseUint(c, 16, 24)
+		if err != nil || len(c) != 6 {
+			return p, fmt.Errorf("invalid color %q", c)
+		}
+		p[i] = tcell.NewHexColor(int32(rgb))
+	}
+	return p, nil
+}
+
+// tcellDisplay draws the framebuffer to the top left corner of a tcell
+// screen, taking 128x32 cells in both resolutions: in low resolution each
+// pixel is two cells wide, and in high resolution each cell holds two pixels
+// stacked with a half block.
+type tcellDisplay struct {
+	scr tcell.Screen
+}
+
+func (d tcellDisplay) Draw(f chip8.Frame) {
+	color := func(c uint8) tcell.Color {
+		return palette[c&3]
+	}
+
+	if f.Width == 128 {
+		for lin := 0; lin < f.Height; lin += 2 {
+			for col := 0; col < f.Width; col++ {
+				style := tcell.StyleDefault.
+					Foreground(color(f.Pixels[lin][col])).
+					Background(color(f.Pixels[lin+1][col]))
+				d.scr.SetContent(col, lin/2, '▀', nil, style)
+			}
+		}
+		return
+	}
+
+	for lin := 0; lin < f.Height; lin++ {
+		for col := 0; col < f.Width; col++ {
+			style := tcell.StyleDefault.Background(color(f.Pixels[lin][col]))
+			d.scr.SetContent(col*2, lin, ' ', nil, style)
+			d.scr.SetContent(col*2+1, lin, ' ', nil, style)
+		}
+	}
+}
+
+// bell rings the terminal bell when a tone starts. It is the fallback for
+// terminals, which can't play the tone itself.
+type bell struct {
+	scr tcell.Screen
+	on  bool
+}
+
+func (b *bell) Beep(on bool) {
+	if on && !b.on {
+		_ = b.scr.Beep()
+	}
+	b.on = on
+}
+
+// wavOutput is the tone written to a WAV file, with -wav.
+type wavOutput struct {
+	*audio.Tone
+	w *audio.WAVWriter
+	f *os.File
+}
+
+// createWAV starts writing the tone to a WAV file in path.
+func createWAV(path string) (*wavOutput, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := audio.NewWAVWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &wavOutput{Tone: audio.NewTone(w, audio.DefaultFrequency), w: w, f: f}, nil
+}
+
+func (o *wavOutput) close() error {
+	err := o.Tone.Err()
+	if werr := o.w.Close(); err == nil {
+		err = werr
+	}
+	if cerr := o.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/debug"
+)
+
+// disasmPane lists the instructions around PC. While the machine is paused
+// a cursor can be moved through the whole ROM to set breakpoints and run to
+// it.
+type disasmPane struct {
+	listing *debug.Listing
+
+	// cursor is the address of the selected line, PC unless scrolled
+	cursor   uint16
+	scrolled bool
+
+	// state of the machine when the listing was made
+	paused bool
+	pc     uint16
+}
+
+// disasmKeys describes the hotkeys of the pane, shown in its title.
+const disasmKeys = "↑↓ PgUp/PgDn Home F9 break F4 run to"
+
+// key moves the cursor or acts on the line under it, reporting whether the
+// key was used.
+func (p *disasmPane) key(d *debug.Debugger, ev *tcell.EventKey) bool {
+	if !d.Paused() || p.listing == nil {
+		return false
+	}
+
+	switch ev.Key() {
+	case tcell.KeyUp:
+		p.move(-1)
+	case tcell.KeyDown:
+		p.move(1)
+	case tcell.KeyPgUp:
+		p.move(-16)
+	case tcell.KeyPgDn:
+		p.move(16)
+	case tcell.KeyHome:
+		p.scrolled = false
+	case tcell.KeyF9:
+		if !d.ClearBreakpoint(p.cursor) {
+			d.SetBreakpoint(p.cursor)
+		}
+	case tcell.KeyF4:
+		d.RunTo(p.cursor)
+		p.scrolled = false
+	default:
+		return false
+	}
+	return true
+}
+
+// move moves the cursor n lines.
+func (p *disasmPane) move(n int) {
+	p.scrolled = true
+	for ; n < 0; n++ {
+		p.cursor = p.listing.Prev(p.cursor)
+	}
+	for ; n > 0; n-- {
+		p.cursor = p.listing.Next(p.cursor)
+	}
+}
+
+// draw draws the pane in the w x h cells at x, y.
+func (p *disasmPane) draw(scr tcell.Screen, d *debug.Debugger, x, y, w, h int) {
+	m := d.Machine()
+
+	// the program may have changed itself, so the listing is made again
+	// every time the machine stops somewhere else
+	if p.listing == nil || d.Paused() && (!p.paused || m.PC() != p.pc) {
+		p.listing = debug.NewListing(m, d.Symbols())
+	}
+	p.paused, p.pc = d.Paused(), m.PC()
+	if !d.Paused() || !p.scrolled {
+		p.cursor, p.scrolled = m.PC(), false
+	}
+
+	text := func(row int, s string, style tcell.Style) {
+		col := 0
+		for _, r := range s {
+			if col == w {
+				break
+			}
+			scr.SetContent(x+col, y+row, r, nil, style)
+			col++
+		}
+		for ; col < w; col++ {
+			scr.SetContent(x+col, y+row, ' ', nil, style)
+		}
+	}
+
+	text(0, "disasm "+disasmKeys, tcell.StyleDefault.Foreground(tcell.ColorGray))
+	h--
+	y++
+
+	type row struct {
+		text  string
+		style tcell.Style
+	}
+	var rows []row
+	lines, _ := p.listing.Window(p.cursor, h, h)
+	cur := 0
+	for _, l := range lines {
+		if label := p.listing.Label(l.Addr); label != "" {
+			rows = append(rows, row{label + ":", tcell.StyleDefault.Foreground(tcell.ColorDarkCyan)})
+		}
+
+		pcMark, bpMark := ' ', ' '
+		style := tcell.StyleDefault
+		if d.IsBreakpoint(l.Addr) {
+			bpMark = '●'
+			style = style.Foreground(tcell.ColorRed)
+		}
+		if l.Addr == m.PC() {
+			pcMark = '>'
+			style = style.Background(tcell.ColorGreenYellow).Foreground(tcell.ColorBlack)
+		}
+		if l.Addr == p.cursor {
+			cur = len(rows)
+			if p.scrolled {
+				style = style.Reverse(true)
+			}
+		}
+		rows = append(rows, row{fmt.Sprintf("%c%c %04X  %s", pcMark, bpMark, l.Addr, l), style})
+	}
+
+	// keep the cursor in the middle
+	start := cur - h/2
+	if start > len(rows)-h {
+		start = len(rows) - h
+	}
+	if start < 0 {
+		start = 0
+	}
+	for i := 0; i < h; i++ {
+		if start+i < len(rows) {
+			r := rows[start+i]
+			text(i, r.text, r.style)
+		} else {
+			text(i, "", tcell.StyleDefault)
+		}
+	}
+}
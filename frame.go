@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// asciiPixels are the characters of the pixels in ASCII screens, by the
+// planes they are on like palette.
+const asciiPixels = ".#+@"
+
+// frameASCII returns the active part of f as text, a line per row.
+func frameASCII(f chip8.Frame) []byte {
+	var b bytes.Buffer
+	for y := 0; y < f.Height; y++ {
+		for _, c := range f.Pixels[y][:f.Width] {
+			b.WriteByte(asciiPixels[c&3])
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// frameImage returns the active part of f as an image in the colors of
+// palette, with each pixel taking scale x scale pixels of the image.
+func frameImage(f chip8.Frame, scale int) *image.Paletted {
+	pal := make(color.Palette, len(palette))
+	for i, c := range palette {
+		r, g, b := c.RGB()
+		pal[i] = color.RGBA{uint8(r), uint8(g), uint8(b), 0xFF}
+	}
+	img := image.NewPaletted(image.Rect(0, 0, f.Width*scale, f.Height*scale), pal)
+	for y := 0; y < f.Height*scale; y++ {
+		for x := 0; x < f.Width*scale; x++ {
+			img.SetColorIndex(x, y, f.Pixels[y/scale][x/scale]&3)
+		}
+	}
+	return img
+}
+
+// writeScreen writes f to the file named out, as PNG scaled by scale if
+// its name ends in .png and as ASCII otherwise, or as ASCII to stdout if out
+// is "".
+func writeScreen(out string, f chip8.Frame, scale int) error {
+	if !strings.HasSuffix(strings.ToLower(out), ".png") {
+		return writeOutput(out, frameASCII(f))
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, frameImage(f, scale)); err != nil {
+		return err
+	}
+	return writeOutput(out, b.Bytes())
+}
+
+// screenshot writes f as PNG to the first file named after rom, like
+// rom-001.png, that doesn't exist yet.
+func screenshot(rom string, f chip8.Frame, scale int) (string, error) {
+	name, err := newFileName(rom, ".png")
+	if err != nil {
+		return "", err
+	}
+	if err := writeScreen(name, f, scale); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("screenshot saved to %s", name), nil
+}
+
+// newFileName returns the first name of the form rom-001.ext, numbered from
+// 1, that no file has yet.
+func newFileName(rom, ext string) (string, error) {
+	base := strings.TrimSuffix(rom, filepath.Ext(rom))
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s-%03d%s", base, n, ext)
+		_, err := os.Stat(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func Test_frameImage(t *testing.T) {
+	f := chip8.Frame{Width: 128, Height: 64}
+	f.Pixels[0][0] = 1
+	f.Pixels[63][127] = 3
+	f.Pixels[10][20] = 2
+
+	img := frameImage(f, 2)
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 128 {
+		t.Fatalf("want: 256x128, got: %dx%d", b.Dx(), b.Dy())
+	}
+	for _, p := range []struct{ x, y, c int }{
+		{0, 0, 1}, {1, 1, 1}, {2, 0, 0}, {255, 127, 3}, {254, 126, 3}, {41, 21, 2},
+	} {
+		if got := img.ColorIndexAt(p.x, p.y); int(got) != p.c {
+			t.Fatalf("at %d,%d: want: color %d, got: %d", p.x, p.y, p.c, got)
+		}
+	}
+}
+
+func Test_parsePalette(t *testing.T) {
+	p, err := parsePalette("000000, #FFFFFF,ff4500,808080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p[2] != tcell.NewHexColor(0xFF4500) {
+		t.Fatalf("want: %v, got: %v", tcell.NewHexColor(0xFF4500), p[2])
+	}
+
+	for _, s := range []string{"", "000000,FFFFFF,FF4500", "000000,FFFFFF,FF4500,80808", "000000,FFFFFF,FF4500,GGGGGG"} {
+		if _, err := parsePalette(s); err == nil {
+			t.Fatalf("%q: want: error, got: nil", s)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// keyEvent presses or releases a key at a cycle of a headless run.
+type keyEvent struct {
+	cycle int
+	key   uint8
+	down  bool
+}
+
+// parseKeyScript parses the -keys flag, a list of events like "300:+5"
+// separated by commas, to press key 5 before the instruction 300 and
+// "900:-5" to release it. Cycles are in decimal and keys in hex.
+func parseKeyScript(s string) ([]keyEvent, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var events []keyEvent
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		cycle, key, ok := strings.Cut(field, ":")
+		if !ok || len(key) != 2 || key[0] != '+' && key[0] != '-' {
+			return nil, fmt.Errorf("want cycle:+K or cycle:-K, got %q", field)
+		}
+		c, err := strconv.ParseUint(cycle, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cycle in %q", field)
+		}
+		k, err := strconv.ParseUint(key[1:], 16, 4)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key in %q", field)
+		}
+		events = append(events, keyEvent{cycle: int(c), key: uint8(k), down: key[0] == '+'})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].cycle < events[j].cycle })
+	return events, nil
+}
+
+// scriptKeypad is the keypad of a headless run, pressed by a key script.
+type scriptKeypad struct {
+	down   [16]bool
+	events []keyEvent
+}
+
+func (k *scriptKeypad) IsKeyDown(key uint8) bool { return k.down[key&0xF] }
+
+// advance applies the events up to cycle.
+func (k *scriptKeypad) advance(cycle int) {
+	for len(k.events) > 0 && k.events[0].cycle <= cycle {
+		e := k.events[0]
+		k.down[e.key] = e.down
+		k.events = k.events[1:]
+	}
+}
+
+// runHeadless executes cycles instructions of m, or until the program
+// exits, pressing the keys of k along the way. The timers tick every IPF
+// instructions, as they do in Frame, and then the screen is recorded to rec
+// if it isn't nil.
+func runHeadless(m *chip8.Machine, k *scriptKeypad, cycles int, rec *recorder) error {
+	for c := 0; c < cycles && !m.Exited(); c++ {
+		k.advance(c)
+		in := m.InstructionAt(m.PC())
+		if err := m.Step(); err != nil {
+			return err
+		}
+		if m.FrameSteps() < m.IPF() && !(m.Quirks().DisplayWait && in.Op == chip8.OpDRW) {
+			continue
+		}
+		m.Tick()
+		if rec != nil {
+			if err := rec.frame(m.Screen()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
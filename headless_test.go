@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func Test_parseKeyScript(t *testing.T) {
+	got, err := parseKeyScript("300:-5, 100:+5,100:+a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []keyEvent{{100, 0x5, true}, {100, 0xA, true}, {300, 0x5, false}}
+	if len(got) != len(want) {
+		t.Fatalf("want: %v, got: %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want: %v, got: %v", want, got)
+		}
+	}
+
+	for _, s := range []string{"100", "100:5", "100:+G", "x:+5", "-1:+5", "100:+55"} {
+		if _, err := parseKeyScript(s); err == nil {
+			t.Fatalf("%q: want: error, got: nil", s)
+		}
+	}
+}
+
+func Test_runHeadless(t *testing.T) {
+	newMachine := func(t *testing.T, rom []byte, keys string) (*chip8.Machine, *scriptKeypad) {
+		t.Helper()
+		events, err := parseKeyScript(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := &scriptKeypad{events: events}
+		m := chip8.New(chip8.Config{Keypad: k})
+		if err := m.LoadROM(rom); err != nil {
+			t.Fatal(err)
+		}
+		return m, k
+	}
+
+	t.Run("keys", func(t *testing.T) {
+		m, k := newMachine(t, []byte{
+			0xF0, 0x0A, // LD V0, K
+			0xF0, 0x29, // LD F, V0
+			0xD1, 0x15, // DRW V1, V1, 5
+			0x12, 0x06, // JP 0206
+		}, "50:+1,60:-1")
+		if err := runHeadless(m, k, 100, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		want := strings.Join([]string{
+			"..#.....",
+			".##.....",
+			"..#.....",
+			"..#.....",
+			".###....",
+			"........",
+		}, "\n")
+		var rows []string
+		for _, row := range strings.Split(string(frameASCII(m.Screen())), "\n")[:6] {
+			rows = append(rows, row[:8])
+		}
+		if got := strings.Join(rows, "\n"); got != want {
+			t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m, k := newMachine(t, []byte{0xFF, 0xFF}, "")
+		err := runHeadless(m, k, 100, nil)
+		if !errors.Is(err, chip8.ErrUnknownOpcode) {
+			t.Fatalf("want: %v, got: %v", chip8.ErrUnknownOpcode, err)
+		}
+	})
+
+	t.Run("exit", func(t *testing.T) {
+		m, k := newMachine(t, []byte{0x60, 0x01, 0x00, 0xFD, 0x60, 0x02}, "")
+		if err := runHeadless(m, k, 100, nil); err != nil || m.V(0) != 1 {
+			t.Fatalf("want: stopped at EXIT with V0=01, got: V0=%02X, %v", m.V(0), err)
+		}
+	})
+}
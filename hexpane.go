@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/debug"
+)
+
+// hexPane is a hex dump of the memory that follows I, PC or a fixed
+// address, set with the mem command. Bytes changed by the program since it
+// was last resumed or stepped are highlighted.
+type hexPane struct {
+	// follow is "I", "PC", or "" to show addr
+	follow string
+	addr   uint16
+}
+
+// exec runs the commands of the pane, reporting whether line was one of
+// them.
+func (p *hexPane) exec(line string) (string, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.ToLower(fields[0]) != "mem" {
+		return "", false, nil
+	}
+
+	if len(fields) == 1 {
+		p.follow = "I"
+		return "memory follows I", true, nil
+	}
+	switch arg := strings.ToUpper(fields[1]); arg {
+	case "I", "PC":
+		p.follow = arg
+		return "memory follows " + arg, true, nil
+	}
+	addr, err := debug.ParseAddr(fields[1])
+	if err != nil {
+		return "", true, err
+	}
+	p.follow, p.addr = "", addr
+	return fmt.Sprintf("memory at %04X", addr), true, nil
+}
+
+// draw draws the pane in the w x h cells at x, y.
+func (p *hexPane) draw(scr tcell.Screen, d *debug.Debugger, x, y, w, h int) {
+	m := d.Machine()
+
+	addr := p.addr
+	switch p.follow {
+	case "I":
+		addr = m.I()
+	case "PC":
+		addr = m.PC()
+	}
+
+	text := func(col, row int, s string, style tcell.Style) int {
+		for _, r := range s {
+			if col < w {
+				scr.SetContent(x+col, y+row, r, nil, style)
+			}
+			col++
+		}
+		return col
+	}
+	for row := 0; row < h; row++ {
+		text(0, row, strings.Repeat(" ", w), tcell.StyleDefault)
+	}
+
+	title := fmt.Sprintf("memory %04X", addr)
+	if p.follow != "" {
+		title = "memory @" + p.follow
+	}
+	text(0, 0, title+"  :mem addr|I|PC  :poke  :set", tcell.StyleDefault.Foreground(tcell.ColorGray))
+
+	// the row with addr goes second, to show a bit of what comes before
+	start := int(addr&^0xF) - 16
+	if start < 0 {
+		start = 0
+	}
+	for row := 1; row < h && start < 0x10000; row, start = row+1, start+16 {
+		col := text(0, row, fmt.Sprintf("%04X ", start), tcell.StyleDefault.Foreground(tcell.ColorGray))
+		for i := 0; i < 16; i++ {
+			a := uint16(start + i)
+			style := tcell.StyleDefault
+			switch {
+			case a == m.PC() || a == m.PC()+1:
+				style = style.Background(tcell.ColorGreenYellow).Foreground(tcell.ColorBlack)
+			case a == m.I():
+				style = style.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorBlack)
+			}
+			if d.Changed(a) {
+				style = style.Foreground(tcell.ColorYellow).Bold(true)
+			}
+			col = text(col, row, " ", tcell.StyleDefault)
+			col = text(col, row, fmt.Sprintf("%02X", m.Peek(a)), style)
+		}
+	}
+}
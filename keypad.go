@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hexpad is the arrangement of the COSMAC VIP keypad, row by row.
+var hexpad = [4][4]uint8{
+	{0x1, 0x2, 0x3, 0xC},
+	{0x4, 0x5, 0x6, 0xD},
+	{0x7, 0x8, 0x9, 0xE},
+	{0xA, 0x0, 0xB, 0xF},
+}
+
+// layouts are described by the 4 rows of terminal keys that are placed over
+// the rows of hexpad.
+var builtinLayouts = map[string][4]string{
+	"qwerty": {"1234", "qwer", "asdf", "zxcv"},
+}
+
+// layout maps terminal keys to CHIP-8 keys.
+type layout map[rune]uint8
+
+func newLayout(rows [4]string) (layout, error) {
+	l := layout{}
+	for i, row := range rows {
+		if utf8.RuneCountInString(row) != 4 {
+			return nil, fmt.Errorf("row %d: want 4 keys, got %q", i+1, row)
+		}
+		j := 0
+		for _, r := range row {
+			k := hexpad[i][j]
+			l[r] = k
+			l[unicode.ToLower(r)] = k
+			l[unicode.ToUpper(r)] = k
+			j++
+		}
+	}
+	return l, nil
+}
+
+// defaultLayoutsFile is where user defined layouts are looked up when no
+// file is given.
+func defaultLayoutsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ch8", "layouts.json")
+}
+
+// loadLayout returns the layout called name, looking in the JSON file at
+// path before the built-in ones. The file maps layout names to 4 rows:
+//
+//	{"azerty": ["&é\"'", "azer", "qsdf", "wxcv"]}
+//
+// A missing file is not an error.
+func loadLayout(path, name string) (layout, error) {
+	layouts := map[string][4]string{}
+	for k, v := range builtinLayouts {
+		layouts[k] = v
+	}
+
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			user := map[string][4]string{}
+			err = json.Unmarshal(b, &user)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for k, v := range user {
+				layouts[k] = v
+			}
+		}
+	}
+
+	rows, ok := layouts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown keypad layout %q", name)
+	}
+	l, err := newLayout(rows)
+	if err != nil {
+		return nil, fmt.Errorf("layout %q: %w", name, err)
+	}
+	return l, nil
+}
+
+// termKeypad is a keypad fed by terminal key presses. Terminals don't report
+// key releases, so a key is considered down until hold has passed since it
+// was last pressed (or repeated).
+type termKeypad struct {
+	layout layout
+	hold   time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pressed [16]time.Time
+}
+
+func newTermKeypad(l layout, hold time.Duration) *termKeypad {
+	return &termKeypad{
+		layout: l,
+		hold:   hold,
+		now:    time.Now,
+	}
+}
+
+// press handles the terminal key r, reporting whether it is mapped.
+func (kp *termKeypad) press(r rune) bool {
+	k, ok := kp.layout[r]
+	if !ok {
+		return false
+	}
+
+	kp.mu.Lock()
+	kp.pressed[k] = kp.now()
+	kp.mu.Unlock()
+	return true
+}
+
+func (kp *termKeypad) IsKeyDown(k uint8) bool {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	return kp.isDown(k & 0xF)
+}
+
+func (kp *termKeypad) isDown(k uint8) bool {
+	t := kp.pressed[k]
+	return !t.IsZero() && kp.now().Sub(t) < kp.hold
+}
+
+func (kp *termKeypad) state() [16]bool {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	var down [16]bool
+	for k := range down {
+		down[k] = kp.isDown(uint8(k))
+	}
+	return down
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_loadLayout(t *testing.T) {
+	t.Run("builtin", func(t *testing.T) {
+		l, err := loadLayout("", "qwerty")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[rune]uint8{'1': 0x1, '4': 0xC, 'q': 0x4, 'R': 0xD, 's': 0x8, 'x': 0x0, 'v': 0xF}
+		for r, k := range want {
+			if l[r] != k {
+				t.Fatalf("%q: want: %X, got: %X", r, k, l[r])
+			}
+		}
+	})
+
+	t.Run("user defined", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "layouts.json")
+		err := os.WriteFile(path, []byte(`{"azerty": ["&é\"'", "azer", "qsdf", "wxcv"]}`), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := loadLayout(path, "azerty")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l['é'] != 0x2 || l['a'] != 0x4 || l['w'] != 0xA {
+			t.Fatalf("unexpected layout: %v", l)
+		}
+	})
+
+	t.Run("bad row", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "layouts.json")
+		err := os.WriteFile(path, []byte(`{"short": ["123", "qwer", "asdf", "zxcv"]}`), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = loadLayout(path, "short")
+		if err == nil {
+			t.Fatal("want: error, got: nil")
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := loadLayout(filepath.Join(t.TempDir(), "missing.json"), "dvorak")
+		if err == nil {
+			t.Fatal("want: error, got: nil")
+		}
+	})
+}
+
+func Test_termKeypad(t *testing.T) {
+	l, err := loadLayout("", "qwerty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	kp := newTermKeypad(l, 100*time.Millisecond)
+	kp.now = func() time.Time { return now }
+
+	if kp.press('p') {
+		t.Fatal("want: unmapped key ignored")
+	}
+	if !kp.press('w') {
+		t.Fatal("want: mapped key handled")
+	}
+	if !kp.IsKeyDown(0x5) || kp.IsKeyDown(0x4) {
+		t.Fatalf("want: only key 5 down, got: %v", kp.state())
+	}
+
+	now = now.Add(150 * time.Millisecond)
+	if kp.IsKeyDown(0x5) {
+		t.Fatal("want: key 5 released after hold timeout")
+	}
+}
@@ -1,42 +1,247 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/audio"
+	"github.com/igoracmelo/ch8/chip8"
+	"github.com/igoracmelo/ch8/debug"
+	"github.com/igoracmelo/ch8/movie"
 )
 
 func main() {
+	log.SetFlags(0)
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if cmd, ok := subcommands[args[0]]; ok {
+			args = args[1:]
+			if err := cmd(args); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+	if err := emulate(args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// errMovie is reported when trying to load a state, rewind or edit the
+// machine while recording or playing a movie.
+var errMovie = errors.New("not allowed while recording or playing a movie")
+
+// emulate runs a ROM in the terminal, or without it with -headless. It is
+// also the run subcommand.
+func emulate(args []string) error {
 	var step bool
-	var refreshPeriod time.Duration
-	flag.DurationVar(&refreshPeriod, "r", 200*time.Microsecond, "refresh period duration")
-	flag.BoolVar(&step, "step", false, "")
-	flag.Parse()
+	var ipf int
+	var layoutsFile, layoutName string
+	var hold time.Duration
+	var waitRelease bool
+	var wavFile string
+	var quirksName string
+	var symbolsFile string
+	var stateFile, loadStateFile string
+	var rewind int
+	var headless bool
+	var cycles int
+	var keyScript, out string
+	var scale int
+	var paletteColors string
+	var recordPath string
+	var recordMovie, playMovie string
+	var seed uint64
+	var randomName string
+	flag.BoolVar(&step, "step", false, "start paused in the debugger")
+	flag.IntVar(&ipf, "ipf", chip8.DefaultIPF, "instructions executed per 60 Hz frame (CPU clock)")
+	flag.StringVar(&layoutName, "layout", "qwerty", "keypad layout name")
+	flag.StringVar(&layoutsFile, "layouts", defaultLayoutsFile(), "JSON file with user defined keypad layouts")
+	flag.DurationVar(&hold, "hold", 250*time.Millisecond, "how long a key stays down after the terminal reports it")
+	flag.BoolVar(&waitRelease, "wait-release", false, "make LD Vx, K wait for the key to be released, like the COSMAC VIP")
+	flag.StringVar(&wavFile, "wav", "", "also write the audio of the session to this WAV file")
+	flag.StringVar(&quirksName, "quirks", "modern", "quirks of the interpreter to emulate: vip, chip48, schip, xochip or modern")
+	flag.StringVar(&symbolsFile, "symbols", "", "file naming addresses of the program, one \"ADDR name\" per line")
+	flag.StringVar(&stateFile, "state", "", "file saved to with F2 and loaded from with F3 (default ROM file + \".state\")")
+	flag.StringVar(&loadStateFile, "load-state", "", "start from the state saved in this file")
+	flag.IntVar(&rewind, "rewind", 10, "seconds of history kept for stepping back and rewinding, 0 to disable")
+	flag.BoolVar(&headless, "headless", false, "run without a terminal for -cycles instructions, then write the screen to -o")
+	flag.IntVar(&cycles, "cycles", 100000, "instructions executed by -headless")
+	flag.StringVar(&keyScript, "keys", "", "keys pressed by -headless, as \"cycle:+K\" to press K and \"cycle:-K\" to release it, separated by commas")
+	flag.StringVar(&out, "o", "", "file -headless writes the screen to, as PNG if it ends in .png or ASCII otherwise (default stdout)")
+	flag.IntVar(&scale, "scale", 8, "size in pixels of a CHIP-8 pixel in PNG screenshots")
+	flag.StringVar(&paletteColors, "palette", "", "colors of the pixels off, on in plane 1, on in plane 2 and on in both, in hex like \"000000,FFFFFF,FF4500,808080\" (default black, white, orange red and gray)")
+	flag.StringVar(&recordPath, "record", "", "record the frames displayed to this file as an animated GIF if it ends in .gif, or else to this directory as numbered PNGs; F10 starts and stops recording")
+	flag.StringVar(&recordMovie, "record-movie", "", "record the keys pressed in each frame to this movie file, to replay the session with -play-movie")
+	flag.StringVar(&playMovie, "play-movie", "", "replay the keys of this movie file instead of reading the keypad, with the settings it was recorded with")
+	flag.Uint64Var(&seed, "seed", 0, "seed of the random numbers of RND, for runs that are the same every time (default one from the time when playing interactively, or a fixed one with -headless and -record-movie)")
+	flag.StringVar(&randomName, "random", "splitmix", "random number generator of RND: splitmix, or lfsr for the cheap kind of the VIP's time")
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+	if flag.NArg() != 1 {
+		return errors.New("usage: ch8 [run] [flags] rom.ch8, see -help for the flags")
+	}
+	if scale < 1 {
+		return fmt.Errorf("-scale must be at least 1")
+	}
+	if paletteColors != "" {
+		p, err := parsePalette(paletteColors)
+		if err != nil {
+			return fmt.Errorf("-palette: %w", err)
+		}
+		palette = p
+	}
+	if stateFile == "" {
+		stateFile = flag.Arg(0) + ".state"
+	}
 
-	c8 := newChip8()
-	log.SetFlags(0)
+	l, err := loadLayout(layoutsFile, layoutName)
+	if err != nil {
+		return err
+	}
+
+	quirks, err := chip8.PresetQuirks(quirksName)
+	if err != nil {
+		return err
+	}
+
+	random, err := chip8.NewRandom(randomName)
+	if err != nil {
+		return err
+	}
+	if seed == 0 {
+		// runs that must come out the same every time get a fixed seed,
+		// and only interactive play one from the time
+		seed = chip8.DefaultSeed
+		if !headless && recordMovie == "" {
+			seed = uint64(time.Now().UnixNano())
+		}
+	}
+
+	var syms debug.Symbols
+	if symbolsFile != "" {
+		f, err := os.Open(symbolsFile)
+		if err != nil {
+			return err
+		}
+		syms, err = debug.ReadSymbols(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", symbolsFile, err)
+		}
+	}
 
 	b, err := os.ReadFile(flag.Arg(0))
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	cfg := chip8.Config{
+		Quirks:      quirks,
+		WaitRelease: waitRelease,
+		IPF:         ipf,
+		Random:      random,
+		Seed:        seed,
+	}
+	var player *movie.Player
+	if playMovie != "" {
+		if keyScript != "" {
+			return fmt.Errorf("-keys and -play-movie can't be used together")
+		}
+		if loadStateFile != "" {
+			return fmt.Errorf("-load-state and -play-movie can't be used together")
+		}
+		player, err = openMovie(playMovie, b)
+		if err != nil {
+			return err
+		}
+		cfg = player.Header().Config(cfg)
+	}
+
+	// the keypad of the machine: the script or the terminal, unless a
+	// movie is played, and recorded to a movie if asked
+	var script *scriptKeypad
+	var keypad *termKeypad
+	if headless {
+		keys, err := parseKeyScript(keyScript)
+		if err != nil {
+			return fmt.Errorf("-keys: %w", err)
+		}
+		script = &scriptKeypad{events: keys}
+		cfg.Keypad = script
+	} else {
+		keypad = newTermKeypad(l, hold)
+		cfg.Keypad = keypad
+	}
+	if player != nil {
+		cfg.Keypad = player
+	}
+	var mov *movieFile
+	if recordMovie != "" {
+		if loadStateFile != "" {
+			return fmt.Errorf("-load-state and -record-movie can't be used together")
+		}
+		mov, err = createMovie(recordMovie, movie.NewHeader(cfg, b), cfg.Keypad)
+		if err != nil {
+			return err
+		}
+		cfg.Keypad = mov
+	}
+
+	var wav *wavOutput
+	if wavFile != "" {
+		wav, err = createWAV(wavFile)
+		if err != nil {
+			return err
+		}
 	}
 
-	copy(c8.ram[0x200:], b)
+	if headless {
+		if wav != nil {
+			cfg.Beeper = wav
+		}
+		m := chip8.New(cfg)
+		if err := m.LoadROM(b); err != nil {
+			return err
+		}
+		if loadStateFile != "" {
+			if _, err := loadState(m, loadStateFile); err != nil {
+				return err
+			}
+		}
+		var rec *recorder
+		if recordPath != "" {
+			rec, err = newRecorder(recordPath, scale)
+			if err != nil {
+				return err
+			}
+		}
+		runErr := runHeadless(m, script, cycles, rec)
+		if err := closeOutputs(rec, mov, wav); err != nil {
+			return err
+		}
+		if err := writeScreen(out, m.Screen(), scale); err != nil {
+			return err
+		}
+		return runErr
+	}
 
 	scr, err := tcell.NewScreen()
 	if err != nil {
-		panic(err)
+		return err
 	}
 	err = scr.Init()
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer scr.Fini()
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -59,374 +264,250 @@ func main() {
 
 	go scr.ChannelEvents(events, quit)
 
-	c8.waitKey = func() {
-		for {
-			ev := <-events
-			if _, ok := ev.(*tcell.EventKey); ok {
-				break
-			}
-			events <- ev
+	var beeper chip8.Beeper = &bell{scr: scr}
+	if wav != nil {
+		beeper = audio.Multi(beeper, wav)
+	}
+
+	cfg.Display = tcellDisplay{scr}
+	cfg.Beeper = beeper
+	c8 := chip8.New(cfg)
+	err = c8.LoadROM(b)
+	if err != nil {
+		scr.Fini()
+		log.Fatal(err)
+	}
+	if loadStateFile != "" {
+		if _, err := loadState(c8, loadStateFile); err != nil {
+			scr.Fini()
+			log.Fatal(err)
 		}
 	}
 
-	c8.isKeyDown = func(k uint8) bool {
-		select {
-		case ev := <-events:
-			// todo
-			_, ok := ev.(*tcell.EventKey)
-			return ok
-		default:
-			return false
+	// a movie only has the keys, so going back in time or editing the
+	// machine while recording or playing one would make it replay
+	// something else
+	inMovie := mov != nil || player != nil
+	if rewind > 0 && !inMovie {
+		c8.SetHistory(chip8.NewHistory(rewind * chip8.TimerHz * c8.IPF()))
+	}
+
+	dbg := debug.New(c8)
+	dbg.SetSymbols(syms)
+	dbg.SetReadOnly(inMovie)
+	if step {
+		dbg.Pause()
+	}
+	var cmd cmdline
+	var disasm disasmPane
+	hex := hexPane{follow: "I"}
+
+	// reported so that a run can be repeated with -seed
+	cmd.report(fmt.Sprintf("seed %d", c8.Seed()), nil)
+
+	var rec *recorder
+	if recordPath != "" {
+		rec, err = newRecorder(recordPath, scale)
+		if err != nil {
+			scr.Fini()
+			log.Fatal(err)
 		}
+		cmd.report("recording to "+recordPath, nil)
 	}
 
-	go func() {
-		for {
-			ev := <-events
+	if player != nil {
+		cmd.report(fmt.Sprintf("playing %s, %d frames", playMovie, player.Len()), nil)
+	}
+	movieEnded := false
+
+	frames := time.NewTicker(time.Second / chip8.TimerHz)
+	defer frames.Stop()
+
+	var halt error
+	for {
+		select {
+		case ev := <-events:
 			switch ev := ev.(type) {
 			case *tcell.EventResize:
 				scr.Sync()
 			case *tcell.EventKey:
-				if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
-					scr.Fini()
-					return
+				if cmd.editing {
+					line, ok := cmd.key(ev)
+					if ok {
+						halt = nil
+						msg, isHex, err := hex.exec(line)
+						if !isHex {
+							msg, err = dbg.Exec(line)
+						}
+						if (errors.Is(err, debug.ErrNoHistory) && inMovie) || errors.Is(err, debug.ErrReadOnly) {
+							err = errMovie
+						}
+						cmd.report(msg, err)
+					}
+					break
 				}
 
-				for i := range c8.keypad {
-					c8.keypad[i] = true
+				if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
+					return closeOutputs(rec, mov, wav)
+				}
+				if disasm.key(dbg, ev) {
+					break
+				}
+				if ev.Key() == tcell.KeyF2 {
+					cmd.report(saveState(c8, stateFile))
+					break
+				}
+				if ev.Key() == tcell.KeyF12 {
+					cmd.report(screenshot(flag.Arg(0), c8.Screen(), scale))
+					break
+				}
+				if ev.Key() == tcell.KeyF10 {
+					rec, err = toggleRecording(rec, flag.Arg(0), scale)
+					if err != nil {
+						cmd.report("", err)
+					} else if rec != nil {
+						cmd.report("recording to "+rec.path, nil)
+					} else {
+						cmd.report("recording stopped", nil)
+					}
+					break
+				}
+				if ev.Key() == tcell.KeyF3 && inMovie {
+					cmd.report("", errMovie)
+					break
+				}
+				if ev.Key() == tcell.KeyF3 {
+					halt = nil
+					cmd.report(loadState(c8, stateFile))
+					break
+				}
+				if ok, err := debugKey(dbg, ev); ok {
+					if errors.Is(err, debug.ErrNoHistory) && inMovie {
+						cmd.report("", errMovie)
+						break
+					}
+					halt = err
+					break
 				}
-				// if ev.Rune() == ' ' {
-				// 	step = !step
-				// }
+				if ev.Rune() == ':' {
+					cmd.editing = true
+					break
+				}
+				keypad.press(ev.Rune())
 			}
-		}
-	}()
-
-	for {
-		now := time.Now()
-
-		c8.step()
-		for step {
-			ev := <-events
-			if _, ok := ev.(*tcell.EventKey); ok {
-				break
+		case <-frames.C:
+			running := !dbg.Paused()
+			if err := dbg.Frame(); err != nil {
+				halt = err
 			}
-			events <- ev
-		}
-
-		for lin := range c8.screen {
-			for col := range c8.screen[lin] {
-				if c8.screen[lin][col] {
-					scr.SetContent(col*2, lin, ' ', nil, tcell.StyleDefault.Background(tcell.ColorWhite))
-					scr.SetContent(col*2+1, lin, ' ', nil, tcell.StyleDefault.Background(tcell.ColorWhite))
-				} else {
-					scr.SetContent(col*2, lin, ' ', nil, tcell.StyleDefault.Background(tcell.ColorBlack))
-					scr.SetContent(col*2+1, lin, ' ', nil, tcell.StyleDefault.Background(tcell.ColorBlack))
+			if rec != nil && running {
+				if err := rec.frame(c8.Screen()); err != nil {
+					cmd.report("", err)
+					rec = nil
 				}
 			}
+			if player != nil && player.Done() && !movieEnded {
+				cmd.report("movie ended", nil)
+				movieEnded = true
+			}
+		}
+
+		if c8.Exited() {
+			setText(83*2, 0, "program exited", tcell.StyleDefault.Foreground(tcell.ColorRed))
+		}
+		clearHalt(scr, 83*2, 38)
+		if halt != nil {
+			drawHalt(scr, 83*2, 38, halt)
+		}
+		cmd.draw(scr, dbg)
+
+		// the panes go below the display, above the command line
+		_, h := scr.Size()
+		if h > 33+3 {
+			disasm.draw(scr, dbg, 0, 33, 44, h-33-3)
+			hex.draw(scr, dbg, 46, 33, 54, h-33-3)
+			drawCallStack(scr, dbg, 102, 33, 26, h-33-3)
 		}
 
-		in := parseOpcode(c8.fetch(c8.pc))
-		if in.id != "" {
+		asm := c8.InstructionAt(c8.PC()).String()
+		if asm != "" {
 			setText(83*2, 2, strings.Repeat(" ", 20), tcell.StyleDefault.Foreground(tcell.ColorGreenYellow))
-			setText(83*2, 2, in.asm, tcell.StyleDefault.Foreground(tcell.ColorGreenYellow))
+			setText(83*2, 2, asm, tcell.StyleDefault.Foreground(tcell.ColorGreenYellow))
 		}
 
 		for x := 0; x <= 0xf; x++ {
-			setText(90*2, 4+2*x, fmt.Sprintf("V%1X: %02X", x, c8.v[x]), tcell.StyleDefault)
+			setText(90*2, 4+2*x, fmt.Sprintf("V%1X: %02X", x, c8.V(uint8(x))), tcell.StyleDefault)
 		}
+		keys := keypad.state()
 		for x := 0; x <= 0xf; x++ {
 			k := 0
-			if c8.keypad[x] {
+			if keys[x] {
 				k = 1
 			}
 			setText(83*2, 4+2*x, fmt.Sprintf("K%1X: %1X", x, k), tcell.StyleDefault)
 		}
-		setText(98*2, 4, fmt.Sprintf("PC: %04X", c8.pc), tcell.StyleDefault)
-		setText(98*2, 6, fmt.Sprintf("I:   %03X", c8.i), tcell.StyleDefault)
-		setText(98*2, 8, fmt.Sprintf("RET: %03X", c8.stack[c8.sp]), tcell.StyleDefault)
-		setText(98*2, 10, fmt.Sprintf("DT:  %02X", c8.dt), tcell.StyleDefault)
-		setText(98*2, 12, fmt.Sprintf("ST:  %02X", c8.st), tcell.StyleDefault)
-		setText(98*2, 14, fmt.Sprintf("[I]: %02X", c8.ram[c8.i]), tcell.StyleDefault)
-		setText(98*2, 16, fmt.Sprintf("[PC]: %04X", c8.fetch(c8.pc)), tcell.StyleDefault)
+		setText(98*2, 4, fmt.Sprintf("PC: %04X", c8.PC()), tcell.StyleDefault)
+		setText(98*2, 6, fmt.Sprintf("I:   %03X", c8.I()), tcell.StyleDefault)
+		ret := uint16(0)
+		if c8.SP() > 0 {
+			ret = c8.Stack()[c8.SP()-1]
+		}
+		setText(98*2, 8, fmt.Sprintf("RET: %03X", ret), tcell.StyleDefault)
+		setText(98*2, 10, fmt.Sprintf("DT:  %02X", c8.DT()), tcell.StyleDefault)
+		setText(98*2, 12, fmt.Sprintf("ST:  %02X", c8.ST()), tcell.StyleDefault)
+		setText(98*2, 14, fmt.Sprintf("[I]: %02X", c8.Peek(c8.I())), tcell.StyleDefault)
+		setText(98*2, 16, fmt.Sprintf("[PC]: %04X", c8.Fetch(c8.PC())), tcell.StyleDefault)
 
 		scr.Show()
-		// c.drawToTerminal()
-		time.Sleep(refreshPeriod - time.Since(now))
 	}
 }
 
-// if you blur your vision, you'll see it a little better
-var fontSet = []byte{
-	// 0
-	0b11110000,
-	0b10010000,
-	0b10010000,
-	0b10010000,
-	0b11110000,
-
-	// 1
-	0b00100000,
-	0b01100000,
-	0b00100000,
-	0b00100000,
-	0b01110000,
-
-	// 2
-	0b11110000,
-	0b00010000,
-	0b11110000,
-	0b10000000,
-	0b11110000,
-
-	// 3
-	0b11110000,
-	0b00010000,
-	0b11110000,
-	0b00010000,
-	0b11110000,
-
-	// 4
-	0b10010000,
-	0b10010000,
-	0b11110000,
-	0b00010000,
-	0b00010000,
-
-	// 5
-	0b11110000,
-	0b10000000,
-	0b11110000,
-	0b00010000,
-	0b11110000,
-
-	// 6
-	0b11110000,
-	0b10000000,
-	0b11110000,
-	0b10010000,
-	0b11110000,
-
-	// 7
-	0b11110000,
-	0b00010000,
-	0b00100000,
-	0b01000000,
-	0b01000000,
-
-	// 8
-	0b11110000,
-	0b10010000,
-	0b11110000,
-	0b10010000,
-	0b11110000,
-
-	// 9
-	0b11110000,
-	0b10010000,
-	0b11110000,
-	0b00010000,
-	0b11110000,
-
-	// A
-	0b11110000,
-	0b10010000,
-	0b11110000,
-	0b10010000,
-	0b10010000,
-
-	// B
-	0b11100000,
-	0b10010000,
-	0b11100000,
-	0b10010000,
-	0b11100000,
-
-	// C
-	0b11110000,
-	0b10000000,
-	0b10000000,
-	0b10000000,
-	0b11110000,
-
-	// D
-	0b11100000,
-	0b10010000,
-	0b10010000,
-	0b10010000,
-	0b11100000,
-
-	// E
-	0b11110000,
-	0b10000000,
-	0b11110000,
-	0b10000000,
-	0b11110000,
-
-	// F
-	0b11110000,
-	0b10000000,
-	0b11110000,
-	0b10000000,
-	0b10000000,
-}
-
-func newChip8() *chip8 {
-	c := &chip8{
-		pc: 0x200,
+// closeOutputs closes the files being written that aren't nil, all of them
+// even if some fail, and returns the first error.
+func closeOutputs(rec *recorder, mov *movieFile, wav *wavOutput) error {
+	var err error
+	if rec != nil {
+		err = rec.close()
+	}
+	if mov != nil {
+		if merr := mov.close(); err == nil {
+			err = merr
+		}
 	}
-	copy(c.ram[0:80], fontSet)
-	return c
+	if wav != nil {
+		if werr := wav.close(); err == nil {
+			err = werr
+		}
+	}
+	return err
 }
 
-type chip8 struct {
-	// general purpose registers
-	v [16]uint8
-
-	// I register (?)
-	i uint16
-
-	// delay timer (DT)
-	dt uint8
-
-	// sound timer (ST)
-	st uint8
-
-	// program counter
-	pc uint16
-
-	// stack pointer
-	sp uint16
-
-	// stack where sp points to
-	stack [16]uint16
-
-	// keypad represents the state of the keys
-	keypad [16]bool
-
-	// emulated ram
-	ram [4096]uint8
-
-	// state of screen per pixel (on/off)
-	screen [32][64]bool
-
-	isKeyDown func(k uint8) bool
-	waitKey   func()
+// clearHalt erases the panel drawn by drawHalt.
+func clearHalt(scr tcell.Screen, x, y int) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 40; j++ {
+			scr.SetContent(x+j, y+i, ' ', nil, tcell.StyleDefault)
+		}
+	}
 }
 
-func (c *chip8) fetch(pc uint16) uint16 {
-	hi, lo := c.ram[pc], c.ram[pc+1]
-	return uint16(hi)<<8 | uint16(lo)
-}
+// drawHalt draws the panel telling why the emulation stopped.
+func drawHalt(scr tcell.Screen, x, y int, err error) {
+	style := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	lines := []string{"halted", err.Error()}
 
-func (c *chip8) step() {
-	if c.dt > 0 {
-		c.dt--
-		return
-	}
-
-	op := c.fetch(c.pc)
-	in := parseOpcode(op)
-	c.pc += 2
-	if in.id == "" {
-		log.Panicf("unknown opcode %04x, skipping\n", op)
-		c.pc += 2
-		return
-	}
-
-	// log.Print()
-	c.print("\033[1;33m" + in.asm + "\033[0m")
-
-	switch in.id {
-	default:
-		log.Panicf("unknown instruction: %04X, %#v", op, in)
-	case "CLS":
-		c.cls()
-	case "RET":
-		c.ret()
-	case "SYS addr":
-		c.sysAddr(in.addr)
-	case "JP addr":
-		c.jpAddr(in.addr)
-	case "CALL addr":
-		c.callAddr(in.addr)
-	case "SE Vx, byte":
-		c.seVxB(in.x, in.b)
-	case "SNE Vx, byte":
-		c.sneVxB(in.x, in.b)
-	case "SE Vx, Vy":
-		c.seVxVy(in.x, in.y)
-	case "LD Vx, byte":
-		c.ldVxB(in.x, in.b)
-	case "ADD Vx, byte":
-		c.addVxB(in.x, in.b)
-	case "LD Vx, Vy":
-		c.ldVxVy(in.x, in.y)
-	case "OR Vx, Vy":
-		c.orVxVy(in.x, in.y)
-	case "AND Vx, Vy":
-		c.andVxVy(in.x, in.y)
-	case "XOR Vx, Vy":
-		c.xorVxVy(in.x, in.y)
-	case "ADD Vx, Vy":
-		c.addVxVy(in.x, in.y)
-	case "SUB Vx, Vy":
-		c.subVxVy(in.x, in.y)
-	case "SHR Vx {, Vy}":
-		c.shrVx(in.x)
-	case "SUBN Vx, Vy":
-		c.subnVxVy(in.x, in.y)
-	case "SHL Vx {, Vy}":
-		c.shlVx(in.x)
-	case "SNE Vx, Vy":
-		c.sneVxVy(in.x, in.y)
-	case "LD I, addr":
-		c.ldIAddr(in.addr)
-	case "JP V0, addr":
-		c.jpV0Addr(in.addr)
-	case "RND Vx, byte":
-		c.rndVxB(in.x, in.b)
-	case "DRW Vx, Vy, nibble":
-		c.drwVxVyN(in.x, in.y, in.n)
-	case "SKP Vx":
-		c.skpVx(in.x)
-	case "SKNP Vx":
-		c.sknpVx(in.x)
-	case "LD Vx, DT":
-		c.ldVxDT(in.x)
-	case "LD Vx, K":
-		c.ldVxK(in.x)
-	case "LD DT, Vx":
-		c.ldDTVx(in.x)
-	case "LD ST, Vx":
-		c.ldSTVx(in.x)
-	case "ADD I, Vx":
-		c.addIVx(in.x)
-	case "LD F, Vx":
-		c.ldFVx(in.x)
-	case "LD B, Vx":
-		c.ldBVx(in.x)
-	case "LD [I], Vx":
-		c.ldIVx(in.x)
-	case "LD Vx, [I]":
-		c.ldVxI(in.x)
+	var e *chip8.Error
+	if errors.As(err, &e) {
+		lines = []string{
+			"halted: " + e.Err.Error(),
+			fmt.Sprintf("PC: %04X  opcode: %04X", e.PC, e.Opcode),
+		}
 	}
+	lines = append(lines, "press Esc to quit")
 
-}
-
-func (c *chip8) drawToTerminal() {
-	cmd := exec.Command("clear")
-	cmd.Stdout = os.Stdout
-	_ = cmd.Run()
-
-	for x := range c.screen {
-		for _, on := range c.screen[x] {
-			if on {
-				fmt.Print("#")
-			} else {
-				fmt.Print(".")
-			}
+	for i, l := range lines {
+		for j, r := range l {
+			scr.SetContent(x+j, y+i, r, nil, style)
 		}
-		fmt.Println()
 	}
 }
@@ -0,0 +1,266 @@
+// Package movie records the keypad input of a machine frame by frame, to
+// replay it later and reproduce a session exactly.
+//
+// A movie file starts with a header holding everything else the session
+// depends on: the seed of the random numbers, the configuration of the
+// machine and a hash of the ROM. Then come the keys held during each
+// frame, as 16 bit masks with bit k set if key k is down.
+package movie
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+const (
+	magic   = "CH8M"
+	version = 1
+
+	// randomSize is the size of the name of the random number generator
+	// in the header, padded with zeros
+	randomSize = 16
+)
+
+// ErrInvalidMovie is returned by NewPlayer for data that isn't a movie it
+// can read.
+var ErrInvalidMovie = errors.New("invalid movie")
+
+// ErrWrongROM is returned by Header.Check for a ROM other than the one the
+// movie was recorded with.
+var ErrWrongROM = errors.New("movie recorded with a different ROM")
+
+// Header describes the session of a movie.
+type Header struct {
+	Seed        uint64
+	IPF         int
+	Quirks      chip8.Quirks
+	WaitRelease bool
+
+	// Random is the name of the random number generator in
+	// chip8.Randoms, or "" if it is another one.
+	Random string
+
+	// ROM is the SHA-256 hash of the ROM.
+	ROM [sha256.Size]byte
+}
+
+// NewHeader returns the header of a session running rom with cfg. The
+// seed in cfg must not be zero, so that it is known.
+func NewHeader(cfg chip8.Config, rom []byte) Header {
+	ipf := cfg.IPF
+	if ipf <= 0 {
+		ipf = chip8.DefaultIPF
+	}
+	random := ""
+	switch cfg.Random.(type) {
+	case nil, *chip8.SplitMix:
+		random = "splitmix"
+	case *chip8.LFSR:
+		random = "lfsr"
+	}
+	return Header{
+		Seed:        cfg.Seed,
+		IPF:         ipf,
+		Quirks:      cfg.Quirks,
+		WaitRelease: cfg.WaitRelease,
+		Random:      random,
+		ROM:         sha256.Sum256(rom),
+	}
+}
+
+// Config returns cfg with the settings of the header. The random number
+// generator of cfg is kept if the header doesn't name one.
+func (h Header) Config(cfg chip8.Config) chip8.Config {
+	cfg.Seed = h.Seed
+	cfg.IPF = h.IPF
+	cfg.Quirks = h.Quirks
+	cfg.WaitRelease = h.WaitRelease
+	if r, err := chip8.NewRandom(h.Random); err == nil {
+		cfg.Random = r
+	}
+	return cfg
+}
+
+// Check returns ErrWrongROM unless rom is the ROM of the movie.
+func (h Header) Check(rom []byte) error {
+	if sha256.Sum256(rom) != h.ROM {
+		return ErrWrongROM
+	}
+	return nil
+}
+
+func (h Header) marshal() []byte {
+	b := []byte(magic)
+	b = binary.BigEndian.AppendUint16(b, version)
+	b = binary.BigEndian.AppendUint64(b, h.Seed)
+	b = binary.BigEndian.AppendUint32(b, uint32(h.IPF))
+	q := h.Quirks
+	for _, f := range []bool{q.ShiftVy, q.IncrementI, q.IncrementIByX, q.JumpVx, q.ResetVF, q.Clip, q.DisplayWait, h.WaitRelease} {
+		b = append(b, boolByte(f))
+	}
+	var random [randomSize]byte
+	copy(random[:], h.Random)
+	b = append(b, random[:]...)
+	return append(b, h.ROM[:]...)
+}
+
+// headerSize is the size of a marshaled header.
+var headerSize = len(Header{}.marshal())
+
+func unmarshalHeader(b []byte) (Header, error) {
+	var h Header
+	if len(b) < len(magic)+2 || string(b[:len(magic)]) != magic {
+		return h, fmt.Errorf("%w: missing %s header", ErrInvalidMovie, magic)
+	}
+	if v := binary.BigEndian.Uint16(b[len(magic):]); v != version {
+		return h, fmt.Errorf("%w: version %d, want %d", ErrInvalidMovie, v, version)
+	}
+	if len(b) < headerSize {
+		return h, fmt.Errorf("%w: truncated header", ErrInvalidMovie)
+	}
+	b = b[len(magic)+2:]
+	h.Seed = binary.BigEndian.Uint64(b)
+	h.IPF = int(binary.BigEndian.Uint32(b[8:]))
+	b = b[12:]
+	q := &h.Quirks
+	for i, f := range []*bool{&q.ShiftVy, &q.IncrementI, &q.IncrementIByX, &q.JumpVx, &q.ResetVF, &q.Clip, &q.DisplayWait, &h.WaitRelease} {
+		*f = b[i] != 0
+	}
+	b = b[8:]
+	h.Random = string(bytes.TrimRight(b[:randomSize], "\x00"))
+	copy(h.ROM[:], b[randomSize:])
+	if h.IPF <= 0 {
+		return h, fmt.Errorf("%w: IPF %d", ErrInvalidMovie, h.IPF)
+	}
+	if _, err := chip8.NewRandom(h.Random); err != nil && h.Random != "" {
+		return h, fmt.Errorf("%w: %v", ErrInvalidMovie, err)
+	}
+	return h, nil
+}
+
+// Recorder is a chip8.FrameKeypad that records the keys of another keypad.
+// The keys are read once per frame, when the previous frame ends, so the
+// program sees the same keys the movie holds.
+type Recorder struct {
+	keypad chip8.Keypad
+	w      *bufio.Writer
+	keys   uint16
+	frames int
+	err    error
+}
+
+// NewRecorder writes the header h to w, and returns a recorder that writes
+// the keys of keypad after it.
+func NewRecorder(w io.Writer, h Header, keypad chip8.Keypad) (*Recorder, error) {
+	r := &Recorder{keypad: keypad, w: bufio.NewWriter(w)}
+	if _, err := r.w.Write(h.marshal()); err != nil {
+		return nil, err
+	}
+	r.latch()
+	return r, nil
+}
+
+func (r *Recorder) latch() {
+	r.keys = 0
+	for k := uint8(0); k < 16; k++ {
+		if r.keypad.IsKeyDown(k) {
+			r.keys |= 1 << k
+		}
+	}
+}
+
+func (r *Recorder) IsKeyDown(k uint8) bool { return r.keys&(1<<(k&0xF)) != 0 }
+
+func (r *Recorder) EndFrame() {
+	r.write()
+	r.latch()
+}
+
+func (r *Recorder) write() {
+	if r.err != nil {
+		return
+	}
+	r.err = binary.Write(r.w, binary.BigEndian, r.keys)
+	r.frames++
+}
+
+// Frames returns the number of frames recorded.
+func (r *Recorder) Frames() int { return r.frames }
+
+// Close writes the keys of the frame in progress and flushes the movie,
+// returning the first error writing it. It doesn't close the writer.
+func (r *Recorder) Close() error {
+	r.write()
+	if r.err != nil {
+		return r.err
+	}
+	return r.w.Flush()
+}
+
+// Player is a chip8.FrameKeypad that replays the keys of a movie. Once the
+// movie ends no key is down.
+type Player struct {
+	header Header
+	keys   []uint16
+	frame  int
+}
+
+// NewPlayer reads the movie in r.
+func NewPlayer(r io.Reader) (*Player, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	h, err := unmarshalHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	b = b[headerSize:]
+	if len(b)%2 != 0 {
+		return nil, fmt.Errorf("%w: truncated frame", ErrInvalidMovie)
+	}
+	keys := make([]uint16, len(b)/2)
+	if err := binary.Read(bytes.NewReader(b), binary.BigEndian, keys); err != nil {
+		return nil, err
+	}
+	return &Player{header: h, keys: keys}, nil
+}
+
+// Header returns the header of the movie.
+func (p *Player) Header() Header { return p.header }
+
+func (p *Player) IsKeyDown(k uint8) bool {
+	if p.Done() {
+		return false
+	}
+	return p.keys[p.frame]&(1<<(k&0xF)) != 0
+}
+
+func (p *Player) EndFrame() {
+	if !p.Done() {
+		p.frame++
+	}
+}
+
+// Frame returns the number of the frame being played, from 0.
+func (p *Player) Frame() int { return p.frame }
+
+// Len returns the number of frames of the movie.
+func (p *Player) Len() int { return len(p.keys) }
+
+// Done reports whether the movie ended.
+func (p *Player) Done() bool { return p.frame >= len(p.keys) }
+
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
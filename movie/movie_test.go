@@ -0,0 +1,101 @@
+package movie
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+type testKeypad [16]bool
+
+func (k *testKeypad) IsKeyDown(key uint8) bool { return k[key] }
+
+func TestRecorder(t *testing.T) {
+	rom := []byte{0x12, 0x00}
+	cfg := chip8.Config{Quirks: chip8.Presets["schip"], IPF: 3, Seed: 42, Random: &chip8.LFSR{}}
+
+	var buf bytes.Buffer
+	keys := &testKeypad{}
+	r, err := NewRecorder(&buf, NewHeader(cfg, rom), keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys[5] = true
+	if r.IsKeyDown(5) {
+		t.Fatal("want: keys latched until the frame ends, got: key 5 down")
+	}
+	r.EndFrame()
+	keys[5], keys[0xA] = false, true
+	r.EndFrame()
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPlayer(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := p.Header()
+	if got := h.Config(chip8.Config{}); got.Seed != cfg.Seed || got.IPF != cfg.IPF || got.Quirks != cfg.Quirks {
+		t.Fatalf("want: %+v, got: %+v", cfg, got)
+	} else if _, ok := got.Random.(*chip8.LFSR); !ok {
+		t.Fatalf("want: an LFSR, got: %T", got.Random)
+	}
+	if err := h.Check(rom); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Check([]byte{0x12, 0x02}); !errors.Is(err, ErrWrongROM) {
+		t.Fatalf("want: %v, got: %v", ErrWrongROM, err)
+	}
+
+	// the key down in each frame, FF for none
+	want := []uint8{0xFF, 5, 0xA}
+	if p.Len() != len(want) {
+		t.Fatalf("want: %d frames, got: %d", len(want), p.Len())
+	}
+	for frame, key := range want {
+		for k := uint8(0); k < 16; k++ {
+			if p.IsKeyDown(k) != (k == key) {
+				t.Fatalf("frame %d: want: only key %X down, got: key %X down=%v", frame, key, k, p.IsKeyDown(k))
+			}
+		}
+		p.EndFrame()
+	}
+	if !p.Done() || p.IsKeyDown(0xA) {
+		t.Fatal("want: movie ended with no keys down")
+	}
+}
+
+func TestNewPlayer_errors(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := NewRecorder(&buf, NewHeader(chip8.Config{Seed: 1}, nil), &testKeypad{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	movie := buf.Bytes()
+
+	badVersion := append([]byte(nil), movie...)
+	badVersion[5] = 9
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"magic", append([]byte("CH8S"), movie[4:]...)},
+		{"version", badVersion},
+		{"header", movie[:headerSize-1]},
+		{"frame", movie[:len(movie)-1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPlayer(bytes.NewReader(tt.data))
+			if !errors.Is(err, ErrInvalidMovie) {
+				t.Fatalf("want: %v, got: %v", ErrInvalidMovie, err)
+			}
+		})
+	}
+}
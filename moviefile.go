@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/igoracmelo/ch8/chip8"
+	"github.com/igoracmelo/ch8/movie"
+)
+
+// openMovie reads the movie in path, checking that it was recorded with
+// rom.
+func openMovie(path string, rom []byte) (*movie.Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := movie.NewPlayer(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.Header().Check(rom); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// movieFile is a movie being recorded to a file.
+type movieFile struct {
+	*movie.Recorder
+	f *os.File
+}
+
+// createMovie starts recording the keys of keypad to a movie in path.
+func createMovie(path string, h movie.Header, keypad chip8.Keypad) (*movieFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := movie.NewRecorder(f, h, keypad)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &movieFile{Recorder: r, f: f}, nil
+}
+
+func (m *movieFile) close() error {
+	err := m.Recorder.Close()
+	if cerr := m.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// recorder records the frames displayed by the machine, either as an
+// animated GIF or as a directory of numbered PNGs.
+//
+// Low resolution frames are scaled twice as much as high resolution ones,
+// so all the images have the same size.
+type recorder struct {
+	path  string
+	scale int
+	isGIF bool
+
+	// frames recorded so far
+	frames int
+
+	// for GIFs, the distinct frames in a row and the frame each started
+	// at, encoded when the recording is closed
+	screens []chip8.Frame
+	starts  []int
+}
+
+// newRecorder starts a recording to path, a GIF if its name ends in .gif
+// and a directory of PNGs otherwise.
+func newRecorder(path string, scale int) (*recorder, error) {
+	r := &recorder{
+		path:  path,
+		scale: scale,
+		isGIF: strings.HasSuffix(strings.ToLower(path), ".gif"),
+	}
+	if !r.isGIF {
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// frame records the screen displayed during a frame.
+func (r *recorder) frame(f chip8.Frame) error {
+	r.frames++
+	if !r.isGIF {
+		return r.writePNG(f, filepath.Join(r.path, fmt.Sprintf("%06d.png", r.frames)))
+	}
+	// a frame equal to the last one only makes it stay longer
+	if len(r.screens) > 0 && r.screens[len(r.screens)-1] == f {
+		return nil
+	}
+	r.screens = append(r.screens, f)
+	r.starts = append(r.starts, r.frames-1)
+	return nil
+}
+
+func (r *recorder) image(f chip8.Frame) *image.Paletted {
+	if f.Width < 128 {
+		return frameImage(f, 2*r.scale)
+	}
+	return frameImage(f, r.scale)
+}
+
+func (r *recorder) writePNG(f chip8.Frame, name string) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, r.image(f)); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// close ends the recording, writing the GIF.
+func (r *recorder) close() error {
+	if !r.isGIF || len(r.screens) == 0 {
+		return nil
+	}
+
+	// the delays are in hundredths of a second, rounded so they add up to
+	// the time of the frames at 60 Hz
+	cs := func(frame int) int { return frame * 100 / chip8.TimerHz }
+	g := &gif.GIF{}
+	for i, f := range r.screens {
+		end := r.frames
+		if i+1 < len(r.starts) {
+			end = r.starts[i+1]
+		}
+		g.Image = append(g.Image, r.image(f))
+		g.Delay = append(g.Delay, cs(end)-cs(r.starts[i]))
+	}
+
+	out, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(out, g); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// toggleRecording stops rec, or starts a new recording to a GIF named
+// after rom if there is none.
+func toggleRecording(rec *recorder, rom string, scale int) (*recorder, error) {
+	if rec != nil {
+		return nil, rec.close()
+	}
+	name, err := newFileName(rom, ".gif")
+	if err != nil {
+		return nil, err
+	}
+	return newRecorder(name, scale)
+}
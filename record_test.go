@@ -0,0 +1,73 @@
+package main
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+func Test_recorder(t *testing.T) {
+	lores := chip8.Frame{Width: 64, Height: 32}
+	hires := chip8.Frame{Width: 128, Height: 64}
+	hires.Pixels[0][0] = 1
+
+	t.Run("gif", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rec.gif")
+		rec, err := newRecorder(path, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []chip8.Frame{lores, lores, lores, hires, lores} {
+			if err := rec.frame(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rec.close(); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		g, err := gif.DecodeAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 3 frames are 5 hundredths of a second, then 1.67 each
+		want := []int{5, 1, 2}
+		if len(g.Delay) != len(want) || g.Delay[0] != want[0] || g.Delay[1] != want[1] || g.Delay[2] != want[2] {
+			t.Fatalf("want: delays %v, got: %v", want, g.Delay)
+		}
+		for _, img := range g.Image {
+			if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 64 {
+				t.Fatalf("want: 128x64 images, got: %dx%d", b.Dx(), b.Dy())
+			}
+		}
+	})
+
+	t.Run("png", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "frames")
+		rec, err := newRecorder(dir, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []chip8.Frame{lores, lores, hires} {
+			if err := rec.frame(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rec.close(); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"000001.png", "000002.png", "000003.png"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
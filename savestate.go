@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/igoracmelo/ch8/chip8"
+)
+
+// saveState writes the state of m to path.
+func saveState(m *chip8.Machine, path string) (string, error) {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("state saved to %s", path), nil
+}
+
+// loadState restores the state of m saved in path.
+func loadState(m *chip8.Machine, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return fmt.Sprintf("state loaded from %s", path), nil
+}
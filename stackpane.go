@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/igoracmelo/ch8/debug"
+)
+
+// stackDepth is the number of entries in the stack of the machine, and
+// stackWarn the depth from which it is shown as close to overflowing.
+const (
+	stackDepth = 16
+	stackWarn  = 12
+)
+
+// drawCallStack draws the subroutines being executed, innermost first, in
+// the w x h cells at x, y.
+func drawCallStack(scr tcell.Screen, d *debug.Debugger, x, y, w, h int) {
+	m := d.Machine()
+	text := func(row int, s string, style tcell.Style) {
+		col := 0
+		for _, r := range s {
+			if col == w {
+				break
+			}
+			scr.SetContent(x+col, y+row, r, nil, style)
+			col++
+		}
+		for ; col < w; col++ {
+			scr.SetContent(x+col, y+row, ' ', nil, style)
+		}
+	}
+
+	title, style := fmt.Sprintf("calls %d/%d", m.SP(), stackDepth), tcell.StyleDefault.Foreground(tcell.ColorGray)
+	switch {
+	case m.SP() >= stackDepth:
+		title, style = title+" full", tcell.StyleDefault.Foreground(tcell.ColorRed)
+	case m.SP() >= stackWarn:
+		title, style = title+" deep", tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	}
+	text(0, title, style)
+
+	frames := debug.CallStack(m)
+	for row := 1; row < h; row++ {
+		if row-1 >= len(frames) {
+			text(row, "", tcell.StyleDefault)
+			continue
+		}
+		f := frames[row-1]
+		name := "?"
+		if f.Called {
+			name = d.Symbol(f.Target)
+		}
+		text(row, fmt.Sprintf("%-12s ret %04X", name, f.Return), tcell.StyleDefault)
+	}
+}